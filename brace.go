@@ -0,0 +1,373 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"strconv"
+)
+
+// braceRange is a numeric range, such as "{1..10}" or "{01..12}", or a
+// character range, such as "{a..e}", in a brace expression.
+type braceRange struct {
+	numeric bool
+	lo, hi  int // the ends of a numeric range, or the runes of a character range
+	width   int // numbers are padded with zeros to this width, if it isn't zero
+}
+
+// runeRange is a range of runes, from lo to hi inclusive, that matches a single
+// character in a path.
+type runeRange struct {
+	lo, hi rune
+}
+
+// maxBraceDigits limits the length of the numbers in a numeric range so they
+// fit in an int.
+const maxBraceDigits = 18
+
+// hasBraces returns true if the glob pattern has a brace expression, that is,
+// a '{' that isn't escaped or inside a class, and that starts one (see
+// isBraceExpression).
+func (c *config) hasBraces(pattern []rune) bool {
+	if !c.braces {
+		return false
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case c.escape:
+			i++
+		case '[':
+			i += c.classLength(pattern[i:]) - 1
+		case '{':
+			if c.isBraceExpression(pattern[i:]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isBraceExpression returns true if the '{' at the start of the glob pattern
+// starts a brace expression. As in bash, it doesn't if it's closed by a '}'
+// without a comma or a range between them, so "{a}" and "{}" are literal
+// characters, '{' and '}' included. A '{' that isn't closed starts one, which
+// Validate reports.
+func (c *config) isBraceExpression(pattern []rune) bool {
+	end, hasComma := c.braceEnd(pattern)
+	if hasComma || end == len(pattern) {
+		return true
+	}
+
+	_, isRange, _ := c.parseBraceRange(pattern[1:end])
+	return isRange
+}
+
+// braceEnd returns the offset of the '}' that closes the brace expression
+// starting at the '{' at the start of the pattern, or the length of the pattern
+// if it isn't closed. It also returns true if the expression has a comma that
+// isn't inside a nested brace expression.
+func (c *config) braceEnd(pattern []rune) (int, bool) {
+	var depth int
+	var hasComma bool
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case c.escape:
+			i++
+		case '[':
+			i += c.classLength(pattern[i:]) - 1
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i, hasComma
+			}
+		case ',':
+			hasComma = hasComma || depth == 1
+		}
+	}
+
+	return len(pattern), hasComma
+}
+
+// scanBraces reports each unbalanced brace in the glob pattern, and each brace
+// expression that looks like a range but isn't a valid one.
+func (c *config) scanBraces(pattern []rune, report func(*PatternError)) {
+	if !c.braces {
+		return
+	}
+
+	var open []int // offsets of the braces not closed yet
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case c.escape:
+			i++
+		case '[':
+			i += c.classLength(pattern[i:]) - 1
+		case '{':
+			open = append(open, i)
+		case '}':
+			if len(open) == 0 {
+				report(newPatternError(pattern, i, ErrGlobUnopenedBrace))
+				continue
+			}
+
+			start := open[len(open)-1]
+			open = open[:len(open)-1]
+			if _, hasComma := c.braceEnd(pattern[start:]); !hasComma {
+				if _, isRange, valid := c.parseBraceRange(pattern[start+1 : i]); isRange && !valid {
+					report(newPatternError(pattern, start+1, ErrGlobInvalidBraceRange).within(constructBrace, start))
+				}
+			}
+		}
+	}
+
+	for _, start := range open {
+		report(newPatternError(pattern, start, ErrGlobUnclosedBrace))
+	}
+}
+
+// parseBraceRange parses the text between the braces of a brace expression
+// without commas. The text is a range if it contains "..", and the range is
+// valid if it's either two integers, such as "1..10" or "-05..05", or two
+// characters, such as "a..e", that don't span a path separator. Steps, such as
+// "1..10..2", are not supported.
+func (c *config) parseBraceRange(text []rune) (r braceRange, isRange, valid bool) {
+	dots := -1
+	for i := 0; i+1 < len(text); i++ {
+		if text[i] == '.' && text[i+1] == '.' {
+			dots = i
+			break
+		}
+	}
+
+	if dots < 0 {
+		return r, false, false
+	}
+
+	lo, hi := text[:dots], text[dots+2:]
+	if len(lo) == 1 && len(hi) == 1 && !isDigit(lo[0]) && !isDigit(hi[0]) {
+		r.lo, r.hi = int(lo[0]), int(hi[0])
+		if r.lo > r.hi {
+			r.lo, r.hi = r.hi, r.lo
+		}
+
+		valid = !c.isBraceSyntax(lo[0]) && !c.isBraceSyntax(hi[0]) && (r.lo > GlobSeparator || r.hi < GlobSeparator)
+		return r, true, valid
+	}
+
+	loWidth, loPadded, ok := parseBraceNumber(lo, &r.lo)
+	if !ok {
+		return r, true, false
+	}
+
+	hiWidth, hiPadded, ok := parseBraceNumber(hi, &r.hi)
+	if !ok {
+		return r, true, false
+	}
+
+	r.numeric = true
+	if r.lo > r.hi {
+		r.lo, r.hi = r.hi, r.lo
+	}
+
+	// like bash, pad the numbers to the width of the wider end if either end
+	// has a leading zero.
+	if loPadded || hiPadded {
+		r.width = loWidth
+		if hiWidth > r.width {
+			r.width = hiWidth
+		}
+	}
+
+	return r, true, true
+}
+
+// parseBraceNumber parses an integer at one end of a numeric range. It returns
+// the width of the integer, including its sign, and true if it has a leading
+// zero.
+func parseBraceNumber(text []rune, n *int) (width int, padded, ok bool) {
+	digits := text
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+
+	if len(digits) == 0 || len(digits) > maxBraceDigits {
+		return 0, false, false
+	}
+
+	for _, r := range digits {
+		if !isDigit(r) {
+			return 0, false, false
+		}
+	}
+
+	value, err := strconv.Atoi(string(text))
+	if err != nil {
+		return 0, false, false
+	}
+
+	*n = value
+	return len(text), len(digits) > 1 && digits[0] == '0', true
+}
+
+// isDigit returns true if the rune is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isBraceSyntax returns true if the rune can't be one end of a character range
+// in a brace expression.
+func (c *config) isBraceSyntax(r rune) bool {
+	switch r {
+	case '*', '?', '[', ']', '{', '}', ',', GlobSeparator:
+		return true
+	}
+
+	return r == c.escape || r == c.globSeparator
+}
+
+// numericAlternatives returns the strings a numeric range matches as a short
+// list of alternatives, each of which is a sequence of digit ranges. For
+// example, "{1..123}" becomes [1-9], [1-9][0-9], 1[0-1][0-9] and 12[0-3]. A
+// negative number is a '-' followed by the digits of its magnitude.
+func (r braceRange) numericAlternatives() [][]runeRange {
+	var alternatives [][]runeRange
+
+	if r.lo < 0 {
+		// the negative numbers are a '-' followed by a range of magnitudes
+		loMagnitude, hiMagnitude := 1, -r.lo
+		if r.hi < 0 {
+			loMagnitude = -r.hi
+		}
+
+		// the '-' is part of the width of a padded number
+		width := r.width
+		if width > 0 {
+			width--
+		}
+
+		for _, digits := range digitAlternatives(loMagnitude, hiMagnitude, width) {
+			alternatives = append(alternatives, append([]runeRange{{'-', '-'}}, digits...))
+		}
+	}
+
+	if r.hi >= 0 {
+		lo := r.lo
+		if lo < 0 {
+			lo = 0
+		}
+		alternatives = append(alternatives, digitAlternatives(lo, r.hi, r.width)...)
+	}
+
+	return alternatives
+}
+
+// digitAlternatives returns sequences of digit ranges that together match the
+// non-negative integers from lo to hi, inclusive. If width isn't zero, the
+// integers are padded with leading zeros to that width. Otherwise, they have
+// no leading zeros.
+func digitAlternatives(lo, hi, width int) [][]runeRange {
+	pad := func(n, width int) []rune {
+		digits := []rune(strconv.Itoa(n))
+		for len(digits) < width {
+			digits = append([]rune{'0'}, digits...)
+		}
+		return digits
+	}
+
+	if width > 0 {
+		return fixedWidthAlternatives(pad(lo, width), pad(hi, width))
+	}
+
+	// split the range into ranges of numbers with the same number of digits
+	var alternatives [][]runeRange
+	for low, n := lo, 1; low <= hi; n++ {
+		ceiling := 1
+		for i := 0; i < n; i++ {
+			ceiling *= 10
+		}
+
+		if low >= ceiling {
+			continue
+		}
+
+		high := hi
+		if high > ceiling-1 {
+			high = ceiling - 1
+		}
+
+		alternatives = append(alternatives, fixedWidthAlternatives(pad(low, n), pad(high, n))...)
+		low = high + 1
+	}
+
+	return alternatives
+}
+
+// fixedWidthAlternatives returns sequences of digit ranges that together match
+// the strings of digits from lo to hi, which have the same length.
+func fixedWidthAlternatives(lo, hi []rune) [][]runeRange {
+	if len(lo) == 0 {
+		return [][]runeRange{nil}
+	}
+
+	allDigits := func(digits []rune, digit rune) bool {
+		for _, r := range digits {
+			if r != digit {
+				return false
+			}
+		}
+		return true
+	}
+
+	// any digits follow a range of first digits
+	anyDigits := func(first runeRange, n int) []runeRange {
+		digits := []runeRange{first}
+		for i := 0; i < n; i++ {
+			digits = append(digits, runeRange{'0', '9'})
+		}
+		return digits
+	}
+
+	// prefix adds the first digit to each of the alternatives for the rest
+	prefix := func(first rune, rest [][]runeRange) [][]runeRange {
+		for i := range rest {
+			rest[i] = append([]runeRange{{first, first}}, rest[i]...)
+		}
+		return rest
+	}
+
+	if lo[0] == hi[0] {
+		return prefix(lo[0], fixedWidthAlternatives(lo[1:], hi[1:]))
+	}
+
+	var alternatives [][]runeRange
+	first, last := lo[0], hi[0]
+	if !allDigits(lo[1:], '0') {
+		nines := make([]rune, len(lo)-1)
+		for i := range nines {
+			nines[i] = '9'
+		}
+		alternatives = append(alternatives, prefix(lo[0], fixedWidthAlternatives(lo[1:], nines))...)
+		first++
+	}
+
+	var tail [][]runeRange
+	if !allDigits(hi[1:], '9') {
+		zeros := make([]rune, len(hi)-1)
+		for i := range zeros {
+			zeros[i] = '0'
+		}
+		tail = prefix(hi[0], fixedWidthAlternatives(zeros, hi[1:]))
+		last--
+	}
+
+	if first <= last {
+		alternatives = append(alternatives, anyDigits(runeRange{first, last}, len(lo)-1))
+	}
+
+	return append(alternatives, tail...)
+}
@@ -0,0 +1,380 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Verify brace expressions match any of their alternatives.
+// braces are the options that turn on brace expressions.
+var braces = Options{Braces: true}
+
+func TestBraces(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"*.{go,mod,sum}", "main.go", true},
+		{"*.{go,mod,sum}", "go.mod", true},
+		{"*.{go,mod,sum}", "go.sum", true},
+		{"*.{go,mod,sum}", "main.c", false},
+		{"*.{go,mod,sum}", "a/main.go", false},
+		{"{a,b}{c,d}", "bc", true},
+		{"{a,b}{c,d}", "ab", false},
+		{"{a,{b,c}d}", "cd", true},
+		{"{a,{b,c}d}", "a", true},
+		{"{a,{b,c}d}", "ad", false},
+		{"{,x}y", "y", true},
+		{"{,x}y", "xy", true},
+		{"{}", "{}", true},
+		{"{}", "", false},
+		{"{x}", "{x}", true},
+		{"{x}", "x", false},
+		{"{a{b,c}}", "{ab}", true},
+		{"{a{b,c}}", "ab", false},
+		{"{{a,b}}", "{a}", true},
+		{"{x,{a}}", "{a}", true},
+		{"{x,{a}}", "a", false},
+		{"src/{cmd,internal/**}/*.go", "src/cmd/main.go", true},
+		{"src/{cmd,internal/**}/*.go", "src/internal/a/b/c.go", true},
+		{"src/{cmd,internal/**}/*.go", "src/pkg/a.go", false},
+		{"{*.txt,[0-9]?}", "notes.txt", true},
+		{"{*.txt,[0-9]?}", "7z", true},
+		{"{*.txt,[0-9]?}", "z7", false},
+		{"**/{testdata,vendor}/**", "a/b/vendor/c/d.go", true},
+		{"**/{testdata,vendor}/**", "a/b/vendored/c", false},
+		{`\{a,b\}`, "{a,b}", true},
+		{`\{a,b\}`, "a", false},
+		{`{a\,b,c}`, "a,b", true},
+		{`{a\,b,c}`, "c", true},
+		{`{a\},b}`, "a}", true},
+		{"{[},]}", "{}}", true},
+		{"{[},]}", "{,}", true},
+		{"{[},]}", "}", false},
+		{"a,b", "a,b", true},
+
+		// numeric ranges
+		{"file{1..10}.txt", "file1.txt", true},
+		{"file{1..10}.txt", "file10.txt", true},
+		{"file{1..10}.txt", "file11.txt", false},
+		{"file{1..10}.txt", "file01.txt", false},
+		{"file{1..10}.txt", "file0.txt", false},
+		{"{01..12}", "07", true},
+		{"{01..12}", "7", false},
+		{"{01..12}", "12", true},
+		{"{01..12}", "13", false},
+		{"{12..01}", "03", true},
+		{"{-3..3}", "-2", true},
+		{"{-3..3}", "0", true},
+		{"{-3..3}", "-0", false},
+		{"{-3..3}", "-4", false},
+		{"{-05..5}", "-03", true},
+		{"{-05..5}", "003", true},
+		{"{-05..5}", "3", false},
+		{"{998..1002}", "999", true},
+		{"{998..1002}", "1001", true},
+		{"{998..1002}", "1003", false},
+
+		// character ranges
+		{"{a..e}.log", "c.log", true},
+		{"{a..e}.log", "f.log", false},
+		{"{e..a}.log", "a.log", true},
+		{"{A..z}", "_", true},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			matched, count, err := braces.MatchE(test.pattern, test.path)
+			if err != nil {
+				t.Fatalf("Test %s: pattern %s is invalid: %s", name, test.pattern, err)
+			}
+
+			if matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+
+			if matched && count != len([]rune(test.path)) {
+				t.Errorf("Test %s (%s, %s): Expected count %d. Actual %d.", name, test.pattern, test.path, len([]rune(test.path)), count)
+			}
+		})
+	}
+}
+
+// Verify numeric ranges match exactly the numbers bash would expand them to.
+func TestBraceNumericRanges(t *testing.T) {
+	ranges := []struct {
+		lo, hi string
+	}{
+		{"0", "0"},
+		{"1", "9"},
+		{"0", "123"},
+		{"7", "1000"},
+		{"19", "21"},
+		{"100", "199"},
+		{"1", "1009"},
+		{"01", "12"},
+		{"001", "120"},
+		{"-20", "-5"},
+		{"-12", "7"},
+		{"-05", "5"},
+		{"-5", "05"},
+	}
+
+	for i, r := range ranges {
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			pattern := "{" + r.lo + ".." + r.hi + "}"
+			p, err := braces.Compile(pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, pattern, err)
+			}
+
+			lo, _ := strconv.Atoi(r.lo)
+			hi, _ := strconv.Atoi(r.hi)
+			// like bash, pad to the wider end if either end has a leading zero
+			padded := func(s string) bool {
+				s = strings.TrimPrefix(s, "-")
+				return len(s) > 1 && s[0] == '0'
+			}
+
+			width := 0
+			if padded(r.lo) || padded(r.hi) {
+				width = len(r.lo)
+				if len(r.hi) > width {
+					width = len(r.hi)
+				}
+			}
+
+			expected := map[string]bool{}
+			for n := lo; n <= hi; n++ {
+				expected[fmt.Sprintf("%0*d", width, n)] = true
+			}
+
+			// try every number near the range, padded and not
+			for n := lo - 30; n <= hi+30; n++ {
+				for w := 0; w <= 4; w++ {
+					path := fmt.Sprintf("%0*d", w, n)
+					if matched, _ := p.Match(path); matched != expected[path] {
+						t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, pattern, path, expected[path], matched)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Verify brace expressions are validated.
+func TestBracesValidate(t *testing.T) {
+	testIO := []struct {
+		pattern string
+		offset  int
+		kind    error
+	}{
+		{"{a,b}", 0, nil},
+		{"{a,{b,c}}", 0, nil},
+		{"{1..10}", 0, nil},
+		{"{a..z}", 0, nil},
+		{"{..}", 1, ErrGlobInvalidBraceRange},
+		{"{1..}", 1, ErrGlobInvalidBraceRange},
+		{"{1..x}", 1, ErrGlobInvalidBraceRange},
+		{"{1..10..2}", 1, ErrGlobInvalidBraceRange},
+		{"{ab..z}", 1, ErrGlobInvalidBraceRange},
+		{"{*..z}", 1, ErrGlobInvalidBraceRange},
+		{"{!..a}", 1, ErrGlobInvalidBraceRange},
+		{"{1234567890123456789..1}", 1, ErrGlobInvalidBraceRange},
+		{"{a..b,c}", 0, nil},
+		{"{a", 0, ErrGlobUnclosedBrace},
+		{"a{b{c}", 1, ErrGlobUnclosedBrace},
+		{"a}", 1, ErrGlobUnopenedBrace},
+		{"{a}}", 3, ErrGlobUnopenedBrace},
+		{`\{a`, 0, nil},
+		{`a\}`, 0, nil},
+		{"[{]a", 0, nil},
+		{"{[}],x}", 0, nil},
+		{"{[}]", 0, ErrGlobUnclosedBrace},
+		{"a[b{", 4, ErrGlobTruncated},
+		{"{a[b", 0, ErrGlobUnclosedBrace},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			_, err := braces.Validate(test.pattern)
+			if !errors.Is(err, test.kind) || (err == nil) != (test.kind == nil) {
+				t.Fatalf("Test %s (%s): Expected %v. Actual %v.", name, test.pattern, test.kind, err)
+			}
+
+			var patternErr *PatternError
+			if errors.As(err, &patternErr) {
+				if patternErr.Offset != test.offset {
+					t.Errorf("Test %s (%s): Expected offset %d. Actual %d.", name, test.pattern, test.offset, patternErr.Offset)
+				}
+				if patternErr.Pattern != test.pattern {
+					t.Errorf("Test %s (%s): Expected the error to name the pattern. Actual %q.", name, test.pattern, patternErr.Pattern)
+				}
+			}
+
+			errs := braces.ValidateAll(test.pattern)
+			if (len(errs) == 0) != (test.kind == nil) || len(errs) > 0 && !errors.Is(errs[0], test.kind) {
+				t.Errorf("Test %s (%s): Expected ValidateAll to report %v first. Actual %v.", name, test.pattern, test.kind, errs)
+			}
+		})
+	}
+}
+
+// Verify ValidateAll reports every unbalanced brace, in order.
+func TestBracesValidateAll(t *testing.T) {
+	errs := braces.ValidateAll("}{a,b}{1..x}{[")
+
+	expected := []struct {
+		offset int
+		kind   error
+	}{
+		{0, ErrGlobUnopenedBrace},
+		{7, ErrGlobInvalidBraceRange},
+		{12, ErrGlobUnclosedBrace},
+		{14, ErrGlobTruncated},
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors. Actual %d: %v.", len(expected), len(errs), errs)
+	}
+
+	for i, err := range errs {
+		var patternErr *PatternError
+		if !errors.As(err, &patternErr) || patternErr.Offset != expected[i].offset || !errors.Is(err, expected[i].kind) {
+			t.Errorf("Error %d: Expected %v at offset %d. Actual %v.", i, expected[i].kind, expected[i].offset, err)
+		}
+	}
+}
+
+// Verify braces are literal characters unless Braces is set, so the glob
+// patterns that had braces in them before brace expressions were supported
+// keep their meaning.
+func TestBracesOff(t *testing.T) {
+	opts := Options{}
+
+	if matched, _ := Match("{a}", "{a}"); !matched {
+		t.Errorf("Expected \"{a}\" to match itself")
+	}
+
+	if matched, _ := Match("{a}", "a"); matched {
+		t.Errorf("Expected \"{a}\" not to match \"a\"")
+	}
+
+	if _, err := Validate("a}"); err != nil {
+		t.Errorf("Expected \"a}\" to be valid. Actual %s.", err)
+	}
+
+	if matched, _ := opts.Match("*.{go,mod}", "main.{go,mod}"); !matched {
+		t.Errorf("Expected braces to be literal characters")
+	}
+
+	if matched, _ := opts.Match("*.{go,mod}", "main.go"); matched {
+		t.Errorf("Expected braces not to be alternatives")
+	}
+
+	if _, err := opts.Validate("a}{"); err != nil {
+		t.Errorf("Expected unbalanced braces to be valid. Actual %s.", err)
+	}
+
+	if _, err := opts.Validate(`\{`); !errors.Is(err, ErrGlobInvalidEscape) {
+		t.Errorf("Expected a brace not to be escapable. Actual %v.", err)
+	}
+}
+
+// Verify each brace expression is a single capture, whatever alternative it
+// matched.
+func TestBracesCaptures(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected []string
+	}{
+		{"*.{go,mod}", "main.go", []string{"*:main", "{go,mod}:go"}},
+		{"src/**/{a,b*}/?.txt", "src/x/y/bcd/e.txt", []string{"**:x/y", "{a,b*}:bcd", "?:e"}},
+		{"v{1..12}/[a-c]", "v10/b", []string{"{1..12}:10", "[a-c]:b"}},
+		{"{*}*", "{a}bc", []string{"*:a", "*:bc"}},
+		{"{{*,x}}", "{ab}", []string{"{*,x}:ab"}},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p, err := braces.Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			captures, matched := p.MatchCaptures(test.path)
+			if !matched {
+				t.Fatalf("Test %s (%s, %s): Expected a match", name, test.pattern, test.path)
+			}
+
+			var actual []string
+			for _, c := range captures {
+				actual = append(actual, c.Wildcard+":"+test.path[c.ByteStart:c.ByteEnd])
+			}
+
+			if strings.Join(actual, " ") != strings.Join(test.expected, " ") {
+				t.Errorf("Test %s (%s, %s): Expected %q. Actual %q.", name, test.pattern, test.path, test.expected, actual)
+			}
+		})
+	}
+
+	p, _ := braces.Compile("*.{go,mod}")
+	rewritten, matched, err := p.Rewrite("$1_test.$2", "main.go")
+	if err != nil || !matched || rewritten != "main_test.go" {
+		t.Errorf("Expected (main_test.go, true, nil). Actual (%s, %t, %v).", rewritten, matched, err)
+	}
+}
+
+// Verify alternatives aren't expanded, which would take exponential time for
+// these patterns.
+func TestBracesAreNotExpanded(t *testing.T) {
+	pattern := strings.Repeat("{a,a,*}", 40) + "b"
+	path := strings.Repeat("a", 100)
+
+	if matched, _ := braces.Match(pattern, path); matched {
+		t.Errorf("Expected no match")
+	}
+
+	if matched, _ := braces.Match(pattern, path+"b"); !matched {
+		t.Errorf("Expected a match")
+	}
+
+	// {0..999999999999} has a trillion alternatives
+	if matched, _ := braces.Match("x{0..999999999999}", "x123456789012"); !matched {
+		t.Errorf("Expected a match")
+	}
+}
+
+// Verify Match doesn't panic when a glob pattern with braces is invalid.
+func TestBracesInvalidPatterns(t *testing.T) {
+	patterns := []string{"{", "}", "{\\", "{a,", "{a,{b", "{[", "{a..", "{1..x}", "{,}}", "a{{{b"}
+
+	for i, pattern := range patterns {
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if _, err := braces.Validate(pattern); err == nil {
+				t.Errorf("Test %s (%s): Expected the pattern to be invalid", name, pattern)
+			}
+
+			braces.Match(pattern, "a")
+			newPattern(pattern, braces.config()).MatchCaptures("{")
+		})
+	}
+}
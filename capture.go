@@ -0,0 +1,189 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"unicode/utf8"
+)
+
+// Capture describes the part of a path consumed by one wildcard in a glob
+// pattern. The wildcards are '?', '*', '**' and character classes.
+type Capture struct {
+	Wildcard  string // the wildcard as written in the glob pattern, e.g., "*" or "[a-z]"
+	Start     int    // rune offset in the path where the capture starts
+	End       int    // rune offset in the path just past the end of the capture
+	ByteStart int    // byte offset in the path where the capture starts
+	ByteEnd   int    // byte offset in the path just past the end of the capture
+}
+
+// wildcard is a single-character wildcard ('?' or a class) in the head of a
+// chunk. Offset is the number of path characters the head consumes before the
+// wildcard.
+type wildcard struct {
+	text   string
+	offset int
+}
+
+// MatchCaptures is like Match, but on success it also returns what each
+// wildcard in the glob pattern consumed in the path, in the order the
+// wildcards appear in the pattern. Like Match, it assumes the glob pattern is
+// valid.
+func MatchCaptures(patternString, pathString string) ([]Capture, bool) {
+	return newPattern(patternString, defaultConfig).MatchCaptures(pathString)
+}
+
+// MatchCaptures is like Match, but on success it also returns what each
+// wildcard in the compiled glob pattern consumed in the path, in the order the
+// wildcards appear in the pattern.
+//
+// Each '*' and '**' consumes as few characters as it can for the rest of the
+// pattern to match, starting with the leftmost one. For example, matching
+// "src/**/testdata/*.json" against "src/a/b/testdata/x.json" captures "a/b"
+// for the '**' and "x" for the '*'.
+func (p *Pattern) MatchCaptures(pathString string) ([]Capture, bool) {
+	if p.usesProgram(pathString) {
+		return p.programCaptures(pathString)
+	}
+
+	starts := make([]int, len(p.chunks))
+	for i := range starts {
+		starts[i] = -1
+	}
+
+	if matched, _ := p.match(pathString, starts); !matched {
+		return nil, false
+	}
+
+	length := utf8.RuneCountInString(pathString)
+	byteOffsets := byteOffsets(pathString, length)
+
+	var captures []Capture
+	capture := func(text string, start, end int) {
+		captures = append(captures, Capture{
+			Wildcard:  text,
+			Start:     start,
+			End:       end,
+			ByteStart: byteOffsets[start],
+			ByteEnd:   byteOffsets[end],
+		})
+	}
+
+	// A chunk that wasn't visited is a trailing '*' or '**' that matched the
+	// empty string at the end of the path. Captures never include a long-path
+	// prefix.
+	var end int
+	if p.config.windows {
+		_, end = trimLongPathPrefix(pathString)
+	}
+	for i, c := range p.chunks {
+		start := starts[i]
+		if start < 0 {
+			start = length
+		}
+
+		if c.kind != patternSimple {
+			capture(encodeRunes(c.rest[:len(c.rest)-len(c.head)-len(c.tail)]), end, start)
+		}
+
+		for _, w := range p.config.wildcards(c.head) {
+			capture(w.text, start+w.offset, start+w.offset+1)
+		}
+		end = start + p.config.headLength(c.head)
+	}
+
+	return captures, true
+}
+
+// programCaptures is MatchCaptures for a glob pattern compiled into a program.
+// Each brace expression outside of another one is a single capture, so the
+// number of captures doesn't depend on the alternative that matched.
+func (p *Pattern) programCaptures(pathString string) ([]Capture, bool) {
+	slots := make([]int, 2*len(p.prog.captures))
+	if matched, _ := p.match(pathString, slots); !matched {
+		return nil, false
+	}
+
+	byteOffsets := byteOffsets(pathString, utf8.RuneCountInString(pathString))
+
+	captures := make([]Capture, 0, len(p.prog.captures))
+	for i, text := range p.prog.captures {
+		start, end := slots[2*i], slots[2*i+1]
+		captures = append(captures, Capture{
+			Wildcard:  text,
+			Start:     start,
+			End:       end,
+			ByteStart: byteOffsets[start],
+			ByteEnd:   byteOffsets[end],
+		})
+	}
+
+	return captures, true
+}
+
+// byteOffsets returns the byte offset of each of the n runes in the path,
+// followed by the length of the path.
+func byteOffsets(path string, n int) []int {
+	offsets := make([]int, 0, n+1)
+	for i := range path {
+		offsets = append(offsets, i)
+	}
+
+	return append(offsets, len(path))
+}
+
+// wildcards returns the single-character wildcards in the head of a chunk.
+func (c *config) wildcards(head []rune) []wildcard {
+	var wildcards []wildcard
+
+	var offset int
+	for i := 0; i < len(head); i++ {
+		switch head[i] {
+		case c.escape:
+			// the next character is a literal
+			i++
+		case '?':
+			wildcards = append(wildcards, wildcard{text: "?", offset: offset})
+		case '[':
+			_, class := c.getClass(head[i:])
+			wildcards = append(wildcards, wildcard{text: encodeRunes(class), offset: offset})
+			i += len(class) - 1
+		}
+		offset++
+	}
+
+	return wildcards
+}
+
+// headStart returns the byte offset in the path where a simple pattern starts
+// when its match ends at the byte offset end.
+func (c *config) headStart(head []rune, path string, end int) int {
+	for n := c.headLength(head); n > 0 && end > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(path[:end])
+		end -= size
+	}
+
+	return end
+}
+
+// headLength returns the number of path characters matched by a simple
+// pattern. Every literal, '?' and class matches exactly one character.
+func (c *config) headLength(head []rune) int {
+	var length int
+
+	for i := 0; i < len(head); i++ {
+		switch head[i] {
+		case c.escape:
+			if i == len(head)-1 {
+				// a trailing escape character doesn't match anything
+				return length
+			}
+			i++
+		case '[':
+			_, class := c.getClass(head[i:])
+			i += len(class) - 1
+		}
+		length++
+	}
+
+	return length
+}
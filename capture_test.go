@@ -0,0 +1,141 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Verify MatchCaptures reports what each wildcard consumed.
+func TestMatchCaptures(t *testing.T) {
+	// sep converts a path written with '/' to one using the path separator
+	sep := func(path string) string {
+		return strings.ReplaceAll(path, "/", SeparatorString)
+	}
+
+	testIO := []struct {
+		pattern  string
+		path     string
+		matched  bool
+		captures []string // what each wildcard captured
+	}{
+		{
+			pattern: "abc",
+			path:    "abc",
+			matched: true,
+		},
+		{
+			pattern: "abc",
+			path:    "abd",
+		},
+		{
+			pattern:  "a?[bc]*",
+			path:     "axcdef",
+			matched:  true,
+			captures: []string{"x", "c", "def"},
+		},
+		{
+			pattern:  "*",
+			path:     "",
+			matched:  true,
+			captures: []string{""},
+		},
+		{
+			pattern:  "x*",
+			path:     "x",
+			matched:  true,
+			captures: []string{""},
+		},
+		{
+			pattern:  "*a*",
+			path:     "bab",
+			matched:  true,
+			captures: []string{"b", "b"},
+		},
+		{
+			pattern:  "**",
+			path:     "a/b",
+			matched:  true,
+			captures: []string{"a/b"},
+		},
+		{
+			pattern:  "src/**/testdata/*.json",
+			path:     "src/a/b/testdata/x.json",
+			matched:  true,
+			captures: []string{"a/b", "x"},
+		},
+		{
+			pattern:  "**/*.go",
+			path:     "a/b/c.go",
+			matched:  true,
+			captures: []string{"a/b", "c"},
+		},
+		{
+			pattern:  "**a*b**c",
+			path:     "axb/zc",
+			matched:  true,
+			captures: []string{"", "x", "/z"},
+		},
+		{
+			pattern:  "**/[bc]a[!a-qsu-z]/?*.txt",
+			path:     "usr/foo/ba世/界x.txt",
+			matched:  true,
+			captures: []string{"usr/foo", "b", "世", "界", "x"},
+		},
+		{
+			pattern: "**c*t",
+			path:    "a/b/c/ut",
+		},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			path := sep(test.path)
+			captures, matched := MatchCaptures(test.pattern, path)
+			if matched != test.matched {
+				t.Fatalf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, path, test.matched, matched)
+			}
+
+			if len(captures) != len(test.captures) {
+				t.Fatalf("Test %s (%s, %s): Expected %d captures. Actual %d: %v.", name, test.pattern, path, len(test.captures), len(captures), captures)
+			}
+
+			runes := []rune(path)
+			for i, capture := range captures {
+				expected := sep(test.captures[i])
+				if actual := string(runes[capture.Start:capture.End]); actual != expected {
+					t.Errorf("Test %s[%02d] (%s, %s): Expected %q. Actual %q.", name, i+1, test.pattern, path, expected, actual)
+				}
+
+				if actual := path[capture.ByteStart:capture.ByteEnd]; actual != expected {
+					t.Errorf("Test %s[%02d] (%s, %s): Expected bytes %q. Actual %q.", name, i+1, test.pattern, path, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+// Verify each capture names the wildcard that made it.
+func TestMatchCapturesWildcards(t *testing.T) {
+	p := MustCompile("***[!.]?*x")
+	captures, matched := p.MatchCaptures("abcdx")
+	if !matched {
+		t.Fatalf("Expected %s to match", p)
+	}
+
+	expected := []string{"***", "[!.]", "?", "*"}
+	if len(captures) != len(expected) {
+		t.Fatalf("Expected %d captures. Actual %d: %v.", len(expected), len(captures), captures)
+	}
+
+	for i, capture := range captures {
+		if capture.Wildcard != expected[i] {
+			t.Errorf("Capture %d: Expected %s. Actual %s.", i+1, expected[i], capture.Wildcard)
+		}
+	}
+}
@@ -18,10 +18,10 @@ func main() {
 
 	pattern := os.Args[1]
 	path := os.Args[2]
-	count, err := glob.Validate(pattern)
+	_, err := glob.Validate(pattern)
 
 	if err != nil {
-		log.Fatalf("Pattern %s is invalid at position %d: %s.", pattern, count+1, err)
+		log.Fatalf("Pattern is invalid: %s.", err)
 	}
 
 	matched, count := glob.Match(pattern, path)
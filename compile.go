@@ -0,0 +1,192 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Pattern is a compiled glob pattern. It is safe for concurrent use by multiple
+// goroutines.
+//
+// A Pattern is validated once and broken down into its simple, directory and
+// recursive chunks when it is compiled, so matching it against many paths
+// doesn't repeat that work for every path.
+type Pattern struct {
+	source string
+	chunks []chunk
+	config *config
+
+	// prog is the compiled glob pattern if it can't be broken down into
+	// chunks (see needsProgram). It's also compiled for a glob pattern that
+	// can be, but only matches paths with a '/' correctly as a program (see
+	// usesProgram).
+	prog *program
+}
+
+// chunk is one sub-pattern found by nextPattern. The head is the sub-pattern
+// without its leading asterisk(s), tail is the rest of the glob pattern that
+// follows the head, and rest is the glob pattern starting with this chunk,
+// including its leading asterisk(s).
+type chunk struct {
+	kind patternType
+	head []rune
+	tail []rune
+	rest []rune
+}
+
+// Compile validates a glob pattern and, if it is valid, returns a Pattern that
+// can be used to match paths against it.
+func Compile(pattern string) (*Pattern, error) {
+	return Options{}.Compile(pattern)
+}
+
+// MustCompile is like Compile but panics if the glob pattern is invalid. It
+// simplifies safe initialization of global variables holding compiled glob
+// patterns.
+func MustCompile(pattern string) *Pattern {
+	p, err := Compile(pattern)
+	if err != nil {
+		panic(`glob: Compile(` + pattern + `): ` + err.Error())
+	}
+
+	return p
+}
+
+// newPattern breaks a glob pattern down into its chunks or, if needsProgram
+// says it must, compiles it into a program. Either way, it's matched using the
+// given configuration. Like Match, it assumes the glob pattern is valid.
+func newPattern(pattern string, c *config) *Pattern {
+	p := &Pattern{source: pattern, config: c}
+
+	rest := c.runes(pattern)
+	if c.needsProgram(rest) {
+		p.prog = c.compileProgram(rest)
+		return p
+	}
+
+	p.chunks = c.chunks(rest)
+	if _, slash := c.ambiguousHeads(p.chunks); slash {
+		p.prog = c.compileProgram(rest)
+	}
+
+	return p
+}
+
+// chunks breaks a glob pattern down into its chunks.
+func (c *config) chunks(pattern []rune) []chunk {
+	var chunks []chunk
+
+	rest := pattern
+	head, tail, kind := c.nextPattern(rest)
+	chunks = append(chunks, chunk{kind: kind, head: head, tail: tail, rest: rest})
+	for len(tail) > 0 {
+		rest = tail
+		head, tail, kind = c.nextPattern(rest)
+		chunks = append(chunks, chunk{kind: kind, head: head, tail: tail, rest: rest})
+	}
+
+	return chunks
+}
+
+// needsProgram returns true if the glob pattern must be compiled into a
+// program. Brace expressions and extended globs can't be broken down into
+// chunks, and the chunks don't know where a path component starts, which
+// matters when a leading '.' is special. Nor can matchChunks match a head
+// after a wildcard if it has a character that may match both a path separator
+// and another character.
+func (c *config) needsProgram(pattern []rune) bool {
+	if c.noDot || c.noHiddenDirs || c.hasBraces(pattern) || c.hasExtGlob(pattern) {
+		return true
+	}
+
+	ambiguous, _ := c.ambiguousHeads(c.chunks(pattern))
+	return ambiguous
+}
+
+// ambiguousHeads returns true if a character in the head of a chunk after the
+// first wildcard may match both a path separator and another character, such
+// as "[/a]" or, if the case of characters is folded, a letter that's a path
+// separator. A '/' in a glob pattern also matches a '/' in a path that isn't a
+// path separator, but that only matters if the path has a '/', so if there is
+// one in a head, it returns slash true instead.
+func (c *config) ambiguousHeads(chunks []chunk) (ambiguous, slash bool) {
+	for _, chunk := range chunks {
+		if chunk.kind == patternSimple {
+			// nothing before the first wildcard is ever restarted
+			continue
+		}
+
+		head := chunk.head
+		for i := 0; i < len(head); i++ {
+			switch token := head[i]; token {
+			case c.escape:
+				if i+1 < len(head) {
+					i++
+					ambiguous = ambiguous || c.ambiguousLiteral(head[i])
+				}
+			case '[':
+				negated, class := c.getClass(head[i:])
+				for _, separator := range []rune{c.separator, GlobSeparator, '\\'} {
+					ambiguous = ambiguous || c.isSeparator(separator) && c.matchClass(class, separator, negated)
+				}
+				i += len(class) - 1
+			case '?':
+				// '?' never matches a path separator
+			case GlobSeparator, c.globSeparator:
+				slash = slash || !c.isSeparator(token)
+			default:
+				ambiguous = ambiguous || c.ambiguousLiteral(token)
+			}
+		}
+	}
+
+	return ambiguous, slash
+}
+
+// ambiguousLiteral returns true if a literal character in a glob pattern may
+// match both a path separator and another character.
+func (c *config) ambiguousLiteral(token rune) bool {
+	var separator, other bool
+
+	value := token
+	for {
+		if c.isSeparator(value) {
+			separator = true
+		} else {
+			other = true
+		}
+
+		if !c.foldCase {
+			break
+		}
+		if value = unicode.SimpleFold(value); value == token {
+			break
+		}
+	}
+
+	return separator && other
+}
+
+// usesProgram returns true if the path is matched by the program rather than
+// the chunks of the glob pattern.
+func (p *Pattern) usesProgram(path string) bool {
+	return p.prog != nil && (p.chunks == nil || strings.Contains(path, GlobSeparatorString))
+}
+
+// String returns the source text used to compile the glob pattern.
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// chunk returns the i-th chunk of the glob pattern. Past the last chunk, it
+// returns an empty simple chunk, just as nextPattern does for an empty
+// pattern.
+func (p *Pattern) chunk(i int) chunk {
+	if i < len(p.chunks) {
+		return p.chunks[i]
+	}
+
+	return chunk{kind: patternSimple}
+}
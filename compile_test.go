@@ -0,0 +1,102 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Verify Compile rejects invalid patterns and compiled patterns match paths
+// exactly the same way Match does.
+func TestCompile(t *testing.T) {
+	testIO := []struct {
+		pattern string
+		paths   []string
+		err     error
+	}{
+		{
+			pattern: "",
+			paths:   []string{"", "a"},
+		},
+		{
+			pattern: "abc",
+			paths:   []string{"abc", "abcd", "ab"},
+		},
+		{
+			pattern: "*",
+			paths:   []string{"abc", "a" + SeparatorString + "b"},
+		},
+		{
+			pattern: "a*a*a*a*a*",
+			paths:   []string{"aaaaaaaaaa", "aaaa"},
+		},
+		{
+			pattern: "**c*t",
+			paths:   []string{"a" + SeparatorString + "b" + SeparatorString + "cat", "a" + SeparatorString + "b" + SeparatorString + "c" + SeparatorString + "ut"},
+		},
+		{
+			pattern: "**a*b**c",
+			paths:   []string{"axb", "axb" + SeparatorString + "zc"},
+		},
+		{
+			pattern: "/Users/**/[bc]a[!a-qsu-z]/?*.txt",
+			paths:   []string{SeparatorString + "Users" + SeparatorString + "foo" + SeparatorString + "ba世" + SeparatorString + "界.txt"},
+		},
+		{
+			pattern: "a\\b",
+			err:     ErrGlobInvalidEscape,
+		},
+		{
+			pattern: "[abc",
+			err:     ErrGlobTruncated,
+		},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p, err := Compile(test.pattern)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Test %s: Compile(%s): expected error %v. Actual %v.", name, test.pattern, test.err, err)
+			}
+
+			if err != nil {
+				if p != nil {
+					t.Errorf("Test %s: Compile(%s): expected a nil pattern on error", name, test.pattern)
+				}
+				return
+			}
+
+			if p.String() != test.pattern {
+				t.Errorf("Test %s: Expected String() %s. Actual %s.", name, test.pattern, p.String())
+			}
+
+			for i, path := range test.paths {
+				expectedMatch, expectedCount := Match(test.pattern, path)
+				matched, count := p.Match(path)
+				if matched != expectedMatch || count != expectedCount {
+					t.Errorf("Test %s[%02d] (%s, %s): Expected (%t, %d). Actual (%t, %d).", name, i+1, test.pattern, path, expectedMatch, expectedCount, matched, count)
+				}
+			}
+		})
+	}
+}
+
+// Verify MustCompile panics on an invalid pattern.
+func TestMustCompile(t *testing.T) {
+	p := MustCompile("*.go")
+	if matched, _ := p.Match("main.go"); !matched {
+		t.Errorf("Expected *.go to match main.go")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected MustCompile to panic on an invalid pattern")
+		}
+	}()
+	MustCompile("[")
+}
@@ -0,0 +1,161 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect selects the file system conventions used to validate glob patterns
+// and match paths.
+type Dialect int
+
+const (
+	// NativeDialect selects the path separator and reserved symbols of the
+	// operating system the program was built for, and matches paths with
+	// case-sensitive comparisons. It's the dialect used by the package-level
+	// functions.
+	NativeDialect Dialect = iota
+
+	// UnixDialect matches paths that use '/' as their path separator. Only the
+	// nul character is reserved, and matching is case-sensitive.
+	UnixDialect
+
+	// WindowsDialect matches Windows paths:
+	//
+	//   - Both '/' and '\' are path separators in a path. They are both path
+	//     separators in a glob pattern, too, if the escape character isn't '\'.
+	//   - Matching is case-insensitive.
+	//   - A glob pattern may start with a drive designator, such as "C:/" or
+	//     "?:/". A UNC root, such as "//server/share/", matches either
+	//     `\\server\share\` or "//server/share/".
+	//   - The long-path prefixes `\\?\` and `\\?\UNC\` are ignored in paths,
+	//     so "C:/*.txt" matches `\\?\C:\a.txt` and "//server/share/*" matches
+	//     `\\?\UNC\server\share\a.txt`.
+	//   - The symbols reserved by NTFS and the reserved device names, such as
+	//     CON, NUL and COM1, are invalid in a glob pattern.
+	WindowsDialect
+)
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case NativeDialect:
+		return "native"
+	case UnixDialect:
+		return "unix"
+	case WindowsDialect:
+		return "windows"
+	}
+
+	return "Dialect(" + strconv.Itoa(int(d)) + ")"
+}
+
+// Windows device names are reserved, with or without an extension, so
+// "NUL.txt" is a reserved name, too.
+var windowsReservedNames = map[string]struct{}{
+	"CON": exists, "PRN": exists, "AUX": exists, "NUL": exists,
+	"CONIN$": exists, "CONOUT$": exists,
+	"COM1": exists, "COM2": exists, "COM3": exists, "COM4": exists, "COM5": exists,
+	"COM6": exists, "COM7": exists, "COM8": exists, "COM9": exists,
+	"COM¹": exists, "COM²": exists, "COM³": exists,
+	"LPT1": exists, "LPT2": exists, "LPT3": exists, "LPT4": exists, "LPT5": exists,
+	"LPT6": exists, "LPT7": exists, "LPT8": exists, "LPT9": exists,
+	"LPT¹": exists, "LPT²": exists, "LPT³": exists,
+}
+
+// isReservedName returns true if the file or directory name is a Windows
+// device name. Device names are compared without regard to case, any
+// extension, or trailing spaces.
+func isReservedName(name string) bool {
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimRight(name, " ")
+
+	_, ok := windowsReservedNames[strings.ToUpper(name)]
+	return ok
+}
+
+// reservedName returns the offset of the first literal file or directory name
+// in the glob pattern that is reserved by the dialect, or -1 if there isn't
+// one. Names that contain wildcards or classes are not checked.
+func (c *config) reservedName(pattern []rune) int {
+	if !c.windows {
+		return -1
+	}
+
+	var name []rune
+	var isEscaped bool
+
+	start := 0
+	literal := true
+	for i := 0; i <= len(pattern); i++ {
+		if i == len(pattern) || (pattern[i] == GlobSeparator || pattern[i] == c.globSeparator) && !isEscaped {
+			if literal && len(name) > 0 && isReservedName(string(name)) {
+				return start
+			}
+			name = name[:0]
+			start = i + 1
+			literal = true
+			continue
+		}
+
+		token := pattern[i]
+		switch {
+		case isEscaped:
+			isEscaped = false
+			name = append(name, token)
+		case token == c.escape:
+			isEscaped = true
+		case token == '[':
+			// skip the class, which may contain a '/'
+			literal = false
+			i += c.classLength(pattern[i:]) - 1
+		case token == '?' || token == '*' || c.braces && (token == '{' || token == '}') || c.isExtGlob(pattern, i):
+			literal = false
+		default:
+			name = append(name, token)
+		}
+	}
+
+	return -1
+}
+
+// isDriveColon returns true if the colon at the given offset in a glob pattern
+// ends a drive designator. A drive designator is at the start of the glob
+// pattern and is either a letter, a '?', or a class, followed by a colon.
+func (c *config) isDriveColon(pattern []rune, offset int) bool {
+	if offset < 1 || pattern[offset] != ':' {
+		return false
+	}
+
+	if offset == 1 {
+		drive := pattern[0]
+		return drive == '?' || drive >= 'a' && drive <= 'z' || drive >= 'A' && drive <= 'Z'
+	}
+
+	return pattern[0] == '[' && c.classLength(pattern) == offset
+}
+
+// trimLongPathPrefix removes the long-path prefix from a Windows path, and
+// returns the path and the number of runes removed from the start of it. The
+// `\\?\UNC` of a UNC path is replaced with a single separator, so
+// `\\?\UNC\server` becomes `\\server`. Only that replacement allocates memory.
+func trimLongPathPrefix(path string) (string, int) {
+	isSeparator := func(b byte) bool {
+		return b == '\\' || b == '/'
+	}
+
+	if len(path) < 4 || !isSeparator(path[0]) || !isSeparator(path[1]) || path[2] != '?' || !isSeparator(path[3]) {
+		return path, 0
+	}
+
+	if len(path) >= 8 && strings.EqualFold(path[4:7], "UNC") && isSeparator(path[7]) {
+		// the separator before "UNC" and the one after it start the UNC root
+		return path[3:4] + path[7:], 6
+	}
+
+	return path[4:], 4
+}
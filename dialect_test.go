@@ -0,0 +1,259 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Verify Windows paths can be matched on any operating system.
+func TestWindowsDialectMatch(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected bool
+		count    int
+	}{
+		// drive letters
+		{"C:/Users/*/*.txt", `C:\Users\me\notes.txt`, true, 21},
+		{"c:/users/*/*.TXT", `C:\Users\me\notes.txt`, true, 21},
+		{"?:/Windows", `D:\Windows`, true, 10},
+		{"[c-d]:/**", `D:\Windows\System32`, true, 19},
+		{"[c-d]:/**", `E:\Windows\System32`, false, 0},
+		{"C:/*.txt", `C:\Users\notes.txt`, false, 3},
+
+		// UNC roots
+		{"//server/share/*.txt", `\\server\share\a.txt`, true, 20},
+		{"//server/share/*.txt", "//server/share/a.txt", true, 20},
+		{"//*/share/**", `\\Server\Share\a\b.txt`, true, 22},
+
+		// long-path prefixes
+		{"C:/*.txt", `\\?\C:\a.txt`, true, 12},
+		{"C:/**/*.txt", `\\?\C:\a\b.txt`, true, 14},
+		{"//server/share/*.txt", `\\?\UNC\server\share\a.txt`, true, 26},
+		{"//server/share/*.txt", `\\?\unc\SERVER\share\a.txt`, true, 26},
+
+		// mixed separators
+		{"a/b/c", `a\b/c`, true, 5},
+		{"a/*", `a\b/c`, false, 2},
+		{"a/**/c", `a/b\x\c`, true, 7},
+		{"a?b", `a/b`, false, 1},
+		{"a?b", `a\b`, false, 1},
+		{"a[!x]b", `a/b`, false, 1},
+		{"a[!x]b", `a\b`, false, 1},
+		{"a[/]b", `a\b`, true, 3},
+
+		// case-insensitivity
+		{"README.md", "readme.MD", true, 9},
+		{"[a-c]*", "Bar", true, 3},
+		{"[!a-c]*", "Bar", false, 0},
+		{"ß", "ẞ", true, 1},
+		{`\*`, "*", true, 1},
+	}
+
+	opts := Options{Dialect: WindowsDialect}
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p, err := opts.Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s: pattern %s is invalid: %s", name, test.pattern, err)
+			}
+
+			matched, count := p.Match(test.path)
+			if matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+
+			if matched && count != test.count {
+				t.Errorf("Test %s (%s, %s): Expected count %d. Actual %d.", name, test.pattern, test.path, test.count, count)
+			}
+		})
+	}
+}
+
+// Verify the Unix dialect matches Unix paths on any operating system.
+func TestUnixDialectMatch(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"a/*.go", "a/b.go", true},
+		{"a/*.go", `a\b.go`, false},
+		{"*", `a\b`, true},
+		{"README.md", "readme.md", false},
+		{"[a-c]*", "Bar", false},
+		{"C:/*.txt", `\\?\C:\a.txt`, false},
+	}
+
+	opts := Options{Dialect: UnixDialect}
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if matched, _ := opts.Match(test.pattern, test.path); matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+}
+
+// Verify glob patterns are validated with the rules of the dialect.
+func TestDialectValidate(t *testing.T) {
+	testIO := []struct {
+		dialect Dialect
+		pattern string
+		offset  int
+		kind    error
+	}{
+		{WindowsDialect, "C:/Users", 8, nil},
+		{WindowsDialect, "?:/Users", 8, nil},
+		{WindowsDialect, "[cd]:/Users", 11, nil},
+		{WindowsDialect, "C:", 2, nil},
+		{WindowsDialect, "a/C:", 3, ErrGlobReservedSymbol},
+		{WindowsDialect, "CC:", 2, ErrGlobReservedSymbol},
+		{WindowsDialect, "*:", 1, ErrGlobReservedSymbol},
+		{WindowsDialect, "a<b", 1, ErrGlobReservedSymbol},
+		{WindowsDialect, "a|b", 1, ErrGlobReservedSymbol},
+		{WindowsDialect, "a\tb", 1, ErrGlobReservedSymbol},
+		{WindowsDialect, "CON", 0, ErrGlobReservedName},
+		{WindowsDialect, "a/nul.txt", 2, ErrGlobReservedName},
+		{WindowsDialect, "a/Com1 .log/b", 2, ErrGlobReservedName},
+		{WindowsDialect, "a/LPT³", 2, ErrGlobReservedName},
+		{WindowsDialect, "a/CONOUT$", 2, ErrGlobReservedName},
+		{WindowsDialect, "a/CO?", 5, nil},
+		{WindowsDialect, "a/CON*", 6, nil},
+		{WindowsDialect, "a/[C]ON", 7, nil},
+		{WindowsDialect, "a/CONSOLE/COM10", 15, nil},
+		{WindowsDialect, "a<b/CON", 1, ErrGlobReservedSymbol},
+		{WindowsDialect, "CON/a<b", 0, ErrGlobReservedName},
+		{UnixDialect, "a<b:c|d/CON", 11, nil},
+		{UnixDialect, "a\x00b", 1, ErrGlobReservedSymbol},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			opts := Options{Dialect: test.dialect}
+			offset, err := opts.Validate(test.pattern)
+			if offset != test.offset || !errors.Is(err, test.kind) || (err == nil) != (test.kind == nil) {
+				t.Errorf("Test %s (%s, %q): Expected (%d, %v). Actual (%d, %v).", name, test.dialect, test.pattern, test.offset, test.kind, offset, err)
+			}
+
+			errs := opts.ValidateAll(test.pattern)
+			if (len(errs) == 0) != (test.kind == nil) || len(errs) > 0 && !errors.Is(errs[0], test.kind) {
+				t.Errorf("Test %s (%s, %q): Expected ValidateAll to report %v first. Actual %v.", name, test.dialect, test.pattern, test.kind, errs)
+			}
+		})
+	}
+}
+
+// Verify ValidateAll reports reserved names in order with the other errors.
+func TestDialectValidateAll(t *testing.T) {
+	opts := Options{Dialect: WindowsDialect}
+	errs := opts.ValidateAll("a<b/aux/c>d")
+
+	expected := []struct {
+		offset int
+		kind   error
+	}{
+		{1, ErrGlobReservedSymbol},
+		{4, ErrGlobReservedName},
+		{9, ErrGlobReservedSymbol},
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors. Actual %d: %v.", len(expected), len(errs), errs)
+	}
+
+	for i, err := range errs {
+		var patternErr *PatternError
+		if !errors.As(err, &patternErr) || patternErr.Offset != expected[i].offset || !errors.Is(err, expected[i].kind) {
+			t.Errorf("Error %d: Expected %v at offset %d. Actual %v.", i, expected[i].kind, expected[i].offset, err)
+		}
+	}
+}
+
+// Verify captures exclude a long-path prefix.
+func TestWindowsDialectCaptures(t *testing.T) {
+	p, err := Options{Dialect: WindowsDialect}.Compile("**/*.txt")
+	if err != nil {
+		t.Fatalf("Pattern is invalid: %s", err)
+	}
+
+	path := `\\?\C:\a\b.txt`
+	captures, matched := p.MatchCaptures(path)
+	if !matched {
+		t.Fatalf("Expected a match")
+	}
+
+	var actual []string
+	for _, c := range captures {
+		actual = append(actual, path[c.ByteStart:c.ByteEnd])
+	}
+
+	if len(actual) != 2 || actual[0] != `C:\a` || actual[1] != "b" {
+		t.Errorf("Expected captures %q and %q. Actual %q.", `C:\a`, "b", actual)
+	}
+}
+
+// Verify the names of the dialects.
+func TestDialectString(t *testing.T) {
+	testIO := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{NativeDialect, "native"},
+		{UnixDialect, "unix"},
+		{WindowsDialect, "windows"},
+		{Dialect(7), "Dialect(7)"},
+	}
+
+	for _, test := range testIO {
+		if actual := test.dialect.String(); actual != test.expected {
+			t.Errorf("Expected %q. Actual %q.", test.expected, actual)
+		}
+	}
+}
+
+// Verify the Windows dialect never panics, and that Validate and ValidateAll
+// agree about which glob patterns are valid.
+func FuzzWindowsDialect(f *testing.F) {
+	f.Add("C:/**/*.txt", `\\?\C:\a\b.txt`)
+	f.Add("//server/share/*", `\\?\UNC\server\share\a`)
+	f.Add("[cd]:/CON", `\\?\`)
+	f.Add("a/nul.txt/[", `\\?\UNC`)
+
+	opts := Options{Dialect: WindowsDialect}
+	f.Fuzz(func(t *testing.T, pattern, path string) {
+		_, err := opts.Validate(pattern)
+		if errs := opts.ValidateAll(pattern); (err == nil) != (len(errs) == 0) {
+			t.Fatalf("Validate(%q) returned %v, but ValidateAll returned %v", pattern, err, errs)
+		}
+
+		p, err := opts.Compile(pattern)
+		if err != nil {
+			return
+		}
+
+		matched, _ := p.Match(path)
+		captures, captured := p.MatchCaptures(path)
+		if captured != matched {
+			t.Fatalf("MatchCaptures(%q, %q) returned %t, but Match returned %t", pattern, path, captured, matched)
+		}
+
+		for _, c := range captures {
+			if c.ByteStart < 0 || c.ByteStart > c.ByteEnd || c.ByteEnd > len(path) {
+				t.Fatalf("MatchCaptures(%q, %q) returned an invalid capture %+v", pattern, path, c)
+			}
+		}
+	})
+}
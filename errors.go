@@ -2,18 +2,139 @@
 
 package glob
 
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
 type globError string
 
 const (
-	ErrGlobZeroLength     = globError("zero-length pattern")
-	ErrGlobNoLeftBracket  = globError("class must start with a left bracket ('[')")
-	ErrGlobTruncated      = globError("pattern truncated")
-	ErrGlobInvalidEscape  = globError("invalid escape sequence")
-	ErrGlobInvalidRange   = globError("invalid range")
-	ErrGlobReservedSymbol = globError("glob error: reserved symbol found in pattern")
+	ErrGlobZeroLength       = globError("zero-length pattern")
+	ErrGlobNoLeftBracket    = globError("class must start with a left bracket ('[')")
+	ErrGlobTruncated        = globError("pattern truncated")
+	ErrGlobInvalidEscape    = globError("invalid escape sequence")
+	ErrGlobInvalidRange     = globError("invalid range")
+	ErrGlobReservedSymbol   = globError("glob error: reserved symbol found in pattern")
+	ErrGlobReservedName     = globError("reserved device name found in pattern")
+	ErrGlobUnknownClassName = globError("unknown character class name")
+	ErrGlobUnknownProperty  = globError("unknown Unicode property")
+
+	// errors found in brace expressions
+	ErrGlobUnclosedBrace     = globError("'{' without a matching '}'")
+	ErrGlobUnopenedBrace     = globError("'}' without a matching '{'")
+	ErrGlobInvalidBraceRange = globError("invalid range in brace expression")
+
+	// errors found in extended globs
+	ErrGlobUnclosedExtGlob = globError("extended glob without a closing ')'")
+
+	// errors found in rewrite templates
+	ErrGlobBadReference    = globError("malformed wildcard reference")
+	ErrGlobUnknownWildcard = globError("reference to a wildcard not in the pattern")
+
+	// errors found in options
+	ErrGlobInvalidEscapeRune = globError("escape character has a meaning of its own in glob patterns")
+
+	// errors found walking a file system
+	ErrBrokenSymlink = globError("symbolic link to a file that doesn't exist")
 )
 
 // satisfy the error interface
 func (err globError) Error() string {
 	return string(err)
 }
+
+// The constructs that may enclose a syntax error in a glob pattern.
+const (
+	constructClass = "class"
+	constructBrace = "brace expression"
+)
+
+// PatternError describes a syntax error found in a glob pattern. It wraps one
+// of the ErrGlob errors, so errors.Is(err, ErrGlobTruncated) and similar tests
+// work whether or not the caller knows about PatternError.
+type PatternError struct {
+	Pattern    string // the glob pattern that has the error
+	Offset     int    // zero-based rune offset of the error in Pattern
+	ByteOffset int    // zero-based byte offset of the error in Pattern
+	Char       rune   // the offending character, or -1 if the pattern ended too soon
+
+	// Construct names the construct enclosing the error (e.g., "class"), and
+	// ConstructOffset is the zero-based rune offset where it starts. Construct
+	// is empty and ConstructOffset is -1 if the error is not inside one.
+	Construct       string
+	ConstructOffset int
+
+	Kind error // the kind of error, such as ErrGlobInvalidEscape
+}
+
+// newPatternError returns a PatternError found at offset in a sub-pattern. Its
+// Pattern and ByteOffset fields are set when locate is called with the entire
+// glob pattern.
+func newPatternError(pattern []rune, offset int, kind globError) *PatternError {
+	char := rune(-1)
+	if offset < len(pattern) {
+		char = pattern[offset]
+		if isEscapedByte(char) {
+			char = utf8.RuneError
+		}
+	}
+
+	return &PatternError{Offset: offset, Char: char, ConstructOffset: -1, Kind: kind}
+}
+
+// within records that the error is enclosed by a construct, such as a class,
+// that starts at the given offset.
+func (e *PatternError) within(construct string, offset int) *PatternError {
+	e.Construct = construct
+	e.ConstructOffset = offset
+	return e
+}
+
+// shift moves the offsets of an error found in a sub-pattern that starts n
+// runes into the sub-pattern that contains it.
+func (e *PatternError) shift(n int) *PatternError {
+	e.Offset += n
+	if e.ConstructOffset >= 0 {
+		e.ConstructOffset += n
+	}
+	return e
+}
+
+// locate moves the offsets of an error found in the sub-pattern that starts
+// base runes into pattern, and fills in the fields that depend on the entire
+// glob pattern.
+func (e *PatternError) locate(pattern []rune, base int) *PatternError {
+	e.shift(base)
+	e.Pattern = encodeRunes(pattern)
+
+	offset := e.Offset
+	if offset > len(pattern) {
+		offset = len(pattern)
+	}
+	e.ByteOffset = len(encodeRunes(pattern[:offset]))
+	return e
+}
+
+// Error satisfies the error interface. The message includes the kind of error,
+// where it was found, and the construct enclosing it.
+func (e *PatternError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s at offset %d", e.Kind, e.Offset)
+	if e.Char >= 0 {
+		fmt.Fprintf(&b, " (%q)", e.Char)
+	}
+	if e.Construct != "" {
+		fmt.Fprintf(&b, " in %s starting at offset %d", e.Construct, e.ConstructOffset)
+	}
+	fmt.Fprintf(&b, " of pattern %q", e.Pattern)
+
+	return b.String()
+}
+
+// Unwrap returns the kind of error, so errors.Is and errors.As can see it.
+func (e *PatternError) Unwrap() error {
+	return e.Kind
+}
@@ -3,6 +3,7 @@
 package glob
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -44,3 +45,103 @@ func TestErrors(t *testing.T) {
 		})
 	}
 }
+
+// Verify Validate describes errors with a PatternError.
+func TestPatternError(t *testing.T) {
+	testIO := []struct {
+		pattern         string
+		kind            error
+		offset          int
+		byteOffset      int
+		char            rune
+		construct       string
+		constructOffset int
+	}{
+		{
+			pattern:         "\\b",
+			kind:            ErrGlobInvalidEscape,
+			offset:          1,
+			byteOffset:      1,
+			char:            'b',
+			constructOffset: -1,
+		},
+		{
+			pattern:         "世界*\\b",
+			kind:            ErrGlobInvalidEscape,
+			offset:          4,
+			byteOffset:      8,
+			char:            'b',
+			constructOffset: -1,
+		},
+		{
+			pattern:         "ab*[]",
+			kind:            ErrGlobTruncated,
+			offset:          5,
+			byteOffset:      5,
+			char:            -1,
+			construct:       constructClass,
+			constructOffset: 3,
+		},
+		{
+			pattern:         "界*[a\\x]",
+			kind:            ErrGlobInvalidEscape,
+			offset:          5,
+			byteOffset:      7,
+			char:            'x',
+			construct:       constructClass,
+			constructOffset: 2,
+		},
+		{
+			pattern:         "**x[a.-0]",
+			kind:            ErrGlobInvalidRange,
+			offset:          5,
+			byteOffset:      5,
+			char:            '.',
+			construct:       constructClass,
+			constructOffset: 3,
+		},
+		{
+			pattern:         "a*b" + string(rune(0x00)),
+			kind:            ErrGlobReservedSymbol,
+			offset:          3,
+			byteOffset:      3,
+			char:            0x00,
+			constructOffset: -1,
+		},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			_, err := Validate(test.pattern)
+			if !errors.Is(err, test.kind) {
+				t.Fatalf("Test %s (%s): Expected %s. Actual %v.", name, test.pattern, test.kind, err)
+			}
+
+			var actual *PatternError
+			if !errors.As(err, &actual) {
+				t.Fatalf("Test %s (%s): Expected a *PatternError. Actual %T.", name, test.pattern, err)
+			}
+
+			if actual.Pattern != test.pattern {
+				t.Errorf("Test %s: Expected pattern %q. Actual %q.", name, test.pattern, actual.Pattern)
+			}
+
+			if actual.Offset != test.offset || actual.ByteOffset != test.byteOffset {
+				t.Errorf("Test %s (%s): Expected offsets (%d, %d). Actual (%d, %d).", name, test.pattern, test.offset, test.byteOffset, actual.Offset, actual.ByteOffset)
+			}
+
+			if actual.Char != test.char {
+				t.Errorf("Test %s (%s): Expected char %q. Actual %q.", name, test.pattern, test.char, actual.Char)
+			}
+
+			if actual.Construct != test.construct || actual.ConstructOffset != test.constructOffset {
+				t.Errorf("Test %s (%s): Expected construct (%q, %d). Actual (%q, %d).", name, test.pattern, test.construct, test.constructOffset, actual.Construct, actual.ConstructOffset)
+			}
+
+			t.Logf("Test %s: %s", name, err)
+		})
+	}
+}
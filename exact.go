@@ -0,0 +1,90 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// In the exact byte mode, each byte of a string that isn't part of a valid
+// UTF-8 sequence is decoded to a rune of its own, from U+DC80 to U+DCFF, as
+// Python's "surrogateescape" error handler does. Valid UTF-8 never decodes to
+// a surrogate, so two different strings never decode to the same runes, and
+// encodeRunes turns the runes back into the bytes they came from.
+
+// escapedByteBase is added to an invalid byte, which is at least 0x80, to
+// give the rune it's decoded to.
+const escapedByteBase = 0xDC00
+
+// decodeExact decodes a string to runes, one for each valid UTF-8 sequence and
+// one for each byte that isn't part of one.
+func decodeExact(s string) []rune {
+	runes := make([]rune, 0, len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			r = escapedByteBase + rune(s[i])
+		}
+		runes = append(runes, r)
+		i += size
+	}
+
+	return runes
+}
+
+// isEscapedByte returns true if the rune was decoded from a byte that isn't
+// part of a valid UTF-8 sequence.
+func isEscapedByte(r rune) bool {
+	return r >= escapedByteBase+0x80 && r <= escapedByteBase+0xFF
+}
+
+// encodeRunes returns the string the runes were decoded from. Runes decoded
+// from invalid bytes become those bytes again.
+func encodeRunes(runes []rune) string {
+	var b strings.Builder
+	for _, r := range runes {
+		if isEscapedByte(r) {
+			b.WriteByte(byte(r - escapedByteBase))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// runes decodes a path or glob pattern for matching. In the exact byte mode,
+// it uses decodeExact. Otherwise, each invalid byte becomes U+FFFD, as it does
+// when a string is converted to a []rune.
+func (c *config) runes(s string) []rune {
+	if c.exactBytes {
+		return decodeExact(s)
+	}
+
+	return []rune(s)
+}
+
+// appendRunes appends the characters of a path to the slice, decoding them the
+// same way runes does, so a buffer of runes can be reused for each path.
+func (c *config) appendRunes(runes []rune, s string) []rune {
+	for len(s) > 0 {
+		r, size := c.decode(s)
+		runes = append(runes, r)
+		s = s[size:]
+	}
+
+	return runes
+}
+
+// decode returns the first character of a path and the number of bytes it
+// takes up, decoding it the same way runes does. Matching walks a path with
+// decode instead of converting the path to runes, so it doesn't allocate.
+func (c *config) decode(s string) (rune, int) {
+	r, size := utf8.DecodeRuneInString(s)
+	if c.exactBytes && r == utf8.RuneError && size == 1 {
+		r = escapedByteBase + rune(s[0])
+	}
+
+	return r, size
+}
@@ -0,0 +1,146 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDecodeExact(t *testing.T) {
+	testIO := []struct {
+		s        string
+		expected []rune
+	}{
+		{"", []rune{}},
+		{"abc", []rune("abc")},
+		{"é世", []rune("é世")},
+		{"�", []rune{utf8.RuneError}},
+		{"a\xffb", []rune{'a', 0xDCFF, 'b'}},
+		{"\xff\xfe", []rune{0xDCFF, 0xDCFE}},
+		{"\xe2\x82", []rune{0xDCE2, 0xDC82}},             // a truncated sequence
+		{"\xed\xb3\xbf", []rune{0xDCED, 0xDCB3, 0xDCBF}}, // an encoded surrogate
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			actual := decodeExact(test.s)
+			if string(actual) != string(test.expected) || len(actual) != len(test.expected) {
+				t.Errorf("Test %s (%q): Expected %U. Actual %U.", name, test.s, test.expected, actual)
+			}
+
+			if encoded := encodeRunes(actual); encoded != test.s {
+				t.Errorf("Test %s (%q): Expected to encode the runes to the same string. Actual %q.", name, test.s, encoded)
+			}
+		})
+	}
+}
+
+// Verify invalid UTF-8 bytes are matched exactly with ExactBytes, and replaced
+// with U+FFFD without it.
+func TestOptionsExactBytes(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		exact    bool // expected with ExactBytes
+		replaced bool // expected without ExactBytes
+	}{
+		{"a\xffb", "a\xffb", true, true},
+		{"a\xffb", "a\xfeb", false, true},
+		{"a�b", "a\xffb", false, true},
+		{"a�b", "a�b", true, true},
+		{"a?b", "a\xffb", true, true},
+		{"a?b", "a\xff\xfeb", false, false},
+		{"a??b", "a\xff\xfeb", true, true},
+		{"?", "é", true, true},
+		{"??", "é", false, false},
+		{"?", "\xe2\x82", false, false},
+		{"[\xff]", "\xff", true, true},
+		{"[\xff]", "\xfe", false, true},
+		{"[!\xff]", "\xfe", true, false},
+		{"[\x80-\xff]", "\xc0", true, true},
+		{"*.txt", "\xff\xfe.txt", true, true},
+		{"**/\xff/*", "a/b/\xff/c", true, true},
+		{"**/\xff/*", "a/b/\xfe/c", false, true},
+		{"{\xff,x}", "\xfe", false, true},
+		{"[[:alpha:]]", "\xff", false, false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if matched, _ := (Options{ExactBytes: true, Braces: true}).Match(test.pattern, test.path); matched != test.exact {
+				t.Errorf("Test %s (%q, %q): Expected %t with ExactBytes. Actual %t.", name, test.pattern, test.path, test.exact, matched)
+			}
+
+			if matched, _ := (Options{Braces: true}).Match(test.pattern, test.path); matched != test.replaced {
+				t.Errorf("Test %s (%q, %q): Expected %t without ExactBytes. Actual %t.", name, test.pattern, test.path, test.replaced, matched)
+			}
+		})
+	}
+}
+
+// Verify captures, rewrites and errors give the offsets of the original bytes.
+func TestExactBytesOffsets(t *testing.T) {
+	p, err := Options{ExactBytes: true}.Compile("*\xff.txt")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	captures, matched := p.MatchCaptures("\xfe\xff\xff.txt")
+	if !matched || len(captures) != 1 {
+		t.Fatalf("Expected one capture. Actual %+v.", captures)
+	}
+
+	if c := captures[0]; c.Start != 0 || c.End != 2 || c.ByteStart != 0 || c.ByteEnd != 2 {
+		t.Errorf("Expected the capture to be the first two bytes. Actual %+v.", c)
+	}
+
+	rewritten, matched, err := p.Rewrite("$1.md", "a\xfe\xff.txt")
+	if err != nil || !matched || rewritten != "a\xfe.md" {
+		t.Errorf("Expected (%q, true, nil). Actual (%q, %t, %v).", "a\xfe.md", rewritten, matched, err)
+	}
+
+	_, err = Validate("\xff\xfe\\b")
+	var patternErr *PatternError
+	if !errors.As(err, &patternErr) {
+		t.Fatalf("Expected a *PatternError. Actual %v.", err)
+	}
+
+	if patternErr.Pattern != "\xff\xfe\\b" || patternErr.Offset != 3 || patternErr.ByteOffset != 3 || patternErr.Char != 'b' {
+		t.Errorf("Expected the error to locate the invalid escape. Actual %+v.", patternErr)
+	}
+
+	_, err = Validate("\xff[\xfe")
+	if errors.As(err, &patternErr) && patternErr.Pattern != "\xff[\xfe" {
+		t.Errorf("Expected the error to name the pattern. Actual %q.", patternErr.Pattern)
+	}
+}
+
+func FuzzDecodeExact(f *testing.F) {
+	f.Add("a\xffb")
+	f.Add("é\xe2\x82")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		runes := decodeExact(s)
+		if encoded := encodeRunes(runes); encoded != s {
+			t.Fatalf("encodeRunes(decodeExact(%q)) = %q", s, encoded)
+		}
+
+		p, err := Options{ExactBytes: true}.Compile("**")
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		if captures, matched := p.MatchCaptures(s); !matched || captures[0].ByteEnd != len(s) {
+			t.Fatalf("MatchCaptures(%q) returned %+v, %t", s, captures, matched)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+// isExtGlob returns true if an extended glob, such as "@(a|b)" or "!(*.go)",
+// starts at offset i in the glob pattern, that is, if extended globs are
+// recognized and the character there is one of the operators '?', '*', '+',
+// '@' or '!' followed by a '('.
+func (c *config) isExtGlob(pattern []rune, i int) bool {
+	if !c.extGlob || i+1 >= len(pattern) || pattern[i+1] != '(' {
+		return false
+	}
+
+	switch pattern[i] {
+	case '?', '*', '+', '@', '!':
+		return true
+	}
+
+	return false
+}
+
+// hasExtGlob returns true if the glob pattern has an extended glob that isn't
+// escaped or inside a class.
+func (c *config) hasExtGlob(pattern []rune) bool {
+	if !c.extGlob {
+		return false
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == c.escape:
+			i++
+		case pattern[i] == '[':
+			i += c.classLength(pattern[i:]) - 1
+		case c.isExtGlob(pattern, i):
+			return true
+		}
+	}
+
+	return false
+}
+
+// scanExtGlobs reports each extended glob without a closing ')'. A ')' or '|'
+// outside of an extended glob is a literal character, so it's reported only
+// if it's a reserved symbol in the dialect, as '|' is on Windows.
+func (c *config) scanExtGlobs(pattern []rune, report func(*PatternError)) {
+	if !c.extGlob {
+		return
+	}
+
+	var open []int // offsets of the extended globs not closed yet
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == c.escape:
+			if i+1 < len(pattern) && pattern[i+1] == '|' && c.isReservedSymbol('|') {
+				report(newPatternError(pattern, i+1, ErrGlobReservedSymbol))
+			}
+			i++
+		case pattern[i] == '[':
+			i += c.classLength(pattern[i:]) - 1
+		case c.isExtGlob(pattern, i):
+			open = append(open, i)
+			i++ // skip the '('
+		case pattern[i] == ')' && len(open) > 0:
+			open = open[:len(open)-1]
+		case pattern[i] == '|' && len(open) == 0 && c.isReservedSymbol('|'):
+			report(newPatternError(pattern, i, ErrGlobReservedSymbol))
+		}
+	}
+
+	for _, start := range open {
+		report(newPatternError(pattern, start, ErrGlobUnclosedExtGlob))
+	}
+}
+
+// extGlobEnd returns the offset of the ')' that closes the extended glob
+// starting at the start of the pattern, or the length of the pattern if it
+// isn't closed.
+func (c *config) extGlobEnd(pattern []rune) int {
+	var depth int
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == c.escape:
+			i++
+		case pattern[i] == '[':
+			i += c.classLength(pattern[i:]) - 1
+		case c.isExtGlob(pattern, i):
+			depth++
+			i++ // skip the '('
+		case pattern[i] == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(pattern)
+}
@@ -0,0 +1,299 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Verify extended globs match the way they do in bash with extglob set.
+func TestExtGlob(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"@(a|b)", "a", true},
+		{"@(a|b)", "b", true},
+		{"@(a|b)", "", false},
+		{"@(a|b)", "ab", false},
+		{"@(*.go|*.mod)", "go.mod", true},
+		{"?(a)b", "b", true},
+		{"?(a)b", "ab", true},
+		{"?(a)b", "aab", false},
+		{"*(ab|c)", "", true},
+		{"*(ab|c)", "ababc", true},
+		{"*(ab|c)", "abca", false},
+		{"+(ab)", "", false},
+		{"+(ab)", "ab", true},
+		{"+(ab)", "abab", true},
+		{"+(a|b)c", "abbac", true},
+		{"!(*.go)", "a.go", false},
+		{"!(*.go)", "a.txt", true},
+		{"!(*.go)", "", true},
+		{"!(a)", "", true},
+		{"!()", "", false},
+		{"!()", "b", true},
+		{"x!(a|b)y", "xy", true},
+		{"x!(a|b)y", "xay", false},
+		{"x!(a|b)y", "xaby", true},
+		{"x*!(a)y", "xy", true},
+		{"*.!(go|mod)", "main.go", false},
+		{"*!(.go)", "main.go", true}, // '*' matches "main.go" and !() the empty string
+		{"!(*.*)", "main.go", false},
+		{"!(*.*)", "Makefile", true},
+		{"@(!(a))", "", true},
+		{"!(!(a))", "a", true},
+		{"!(!(a))", "b", false},
+		{"*(a*)", "abc", true},
+		{"*(*(a)b)", "aabab", true},
+		{"+(?(a)b)", "babb", true},
+
+		// extended globs don't match path separators unless a pattern does
+		{"!(a)/b", "c/b", true},
+		{"!(a)", "c/b", false},
+		{"*(a|b)", "a/b", false},
+		{"@(a/b|c)/d", "a/b/d", true},
+		{"src/@(cmd|internal/**)/*.go", "src/internal/x/y/z.go", true},
+		{"src/@(cmd|internal/**)/*.go", "src/pkg/z.go", false},
+
+		// brace expressions and extended globs may be nested
+		{"{a,@(b|c)}d", "cd", true},
+		{"@(a|{b,c})d", "bd", true},
+		{"@(a,b|c)", "a,b", true},
+		{"{@(a|b),c}", "a", true},
+
+		// other characters are literal
+		{"file (1).txt", "file (1).txt", true},
+		{"a)|b", "a)|b", true},
+		{"a(b|c)", "a(b|c)", true},
+		{`\@(a)`, "@(a)", true},
+		{`@\(a)`, "@(a)", true},
+		{`@(a\|b)`, "a|b", true},
+		{`@(a\)b)`, "a)b", true},
+		{"[@(]a", "(a", true},
+	}
+
+	opts := Options{ExtGlob: true, Braces: true}
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if _, err := opts.Validate(test.pattern); err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if matched, _ := opts.Match(test.pattern, test.path); matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+}
+
+// Verify extended globs are literal characters unless they are turned on.
+func TestExtGlobOff(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"@(a|b)", "@(a|b)", true},
+		{"@(a|b)", "a", false},
+		{"!(a)", "!(a)", true},
+		{"+(a)", "+(a)", true},
+		{"x*(a)", "xy(a)", true},
+		{"?(a)", "x(a)", true},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if matched, _ := Match(test.pattern, test.path); matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+
+	if _, err := Validate(`\(`); !errors.Is(err, ErrGlobInvalidEscape) {
+		t.Errorf("Expected %v. Actual %v.", ErrGlobInvalidEscape, err)
+	}
+}
+
+// Verify unclosed extended globs and reserved symbols are reported.
+func TestExtGlobValidate(t *testing.T) {
+	testIO := []struct {
+		dialect Dialect
+		pattern string
+		offset  int
+		kind    error
+	}{
+		{UnixDialect, "@(a|b)", 0, nil},
+		{UnixDialect, "@(a", 0, ErrGlobUnclosedExtGlob},
+		{UnixDialect, "x!(a|+(b)", 1, ErrGlobUnclosedExtGlob},
+		{UnixDialect, "a)", 0, nil},
+		{UnixDialect, "a|b", 0, nil},
+		{UnixDialect, "(a)", 0, nil},
+		{UnixDialect, `\@(a`, 0, nil},
+		{UnixDialect, `@\(a`, 0, nil},
+		{UnixDialect, `@(\)`, 0, ErrGlobUnclosedExtGlob},
+		{UnixDialect, "[@(]", 0, nil},
+		{UnixDialect, "@([)", 0, ErrGlobUnclosedExtGlob},
+		{UnixDialect, "@(a[b)", 0, ErrGlobUnclosedExtGlob}, // the class swallows the ')'
+		{UnixDialect, "@(a[b])", 0, nil},
+		{WindowsDialect, "@(a|b)", 0, nil},
+		{WindowsDialect, "a|b", 1, ErrGlobReservedSymbol},
+		{WindowsDialect, "@(a)|b", 4, ErrGlobReservedSymbol},
+		{WindowsDialect, "@(a`|b)", 4, ErrGlobReservedSymbol},
+		{WindowsDialect, "@(a:|b)", 3, ErrGlobReservedSymbol},
+		{WindowsDialect, "@(CON|b)", 0, nil},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			opts := Options{Dialect: test.dialect, ExtGlob: true}
+			if test.dialect == WindowsDialect {
+				opts.Escape = '`'
+			}
+
+			_, err := opts.Validate(test.pattern)
+			if !errors.Is(err, test.kind) || (err == nil) != (test.kind == nil) {
+				t.Fatalf("Test %s (%s): Expected %v. Actual %v.", name, test.pattern, test.kind, err)
+			}
+
+			var patternErr *PatternError
+			if errors.As(err, &patternErr) {
+				if patternErr.Offset != test.offset {
+					t.Errorf("Test %s (%s): Expected offset %d. Actual %d.", name, test.pattern, test.offset, patternErr.Offset)
+				}
+				if patternErr.Pattern != test.pattern {
+					t.Errorf("Test %s (%s): Expected the error to name the pattern. Actual %q.", name, test.pattern, patternErr.Pattern)
+				}
+			}
+
+			errs := opts.ValidateAll(test.pattern)
+			if (len(errs) == 0) != (test.kind == nil) || len(errs) > 0 && !errors.Is(errs[0], test.kind) {
+				t.Errorf("Test %s (%s): Expected ValidateAll to report %v first. Actual %v.", name, test.pattern, test.kind, errs)
+			}
+		})
+	}
+}
+
+// Verify each extended glob outside of another one is a single capture.
+func TestExtGlobCaptures(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected []string
+	}{
+		{"*.@(go|mod)", "main.go", []string{"*:main", "@(go|mod):go"}},
+		{"!(*_test).go", "main.go", []string{"!(*_test):main"}},
+		{"+(ab)*", "ababc", []string{"+(ab):ab", "*:abc"}},
+		{"?(x)*(y)z", "yyz", []string{"?(x):", "*(y):yy"}},
+	}
+
+	opts := Options{ExtGlob: true}
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p, err := opts.Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			captures, matched := p.MatchCaptures(test.path)
+			if !matched {
+				t.Fatalf("Test %s (%s, %s): Expected a match", name, test.pattern, test.path)
+			}
+
+			var actual []string
+			for _, c := range captures {
+				actual = append(actual, c.Wildcard+":"+test.path[c.ByteStart:c.ByteEnd])
+			}
+
+			if strings.Join(actual, " ") != strings.Join(test.expected, " ") {
+				t.Errorf("Test %s (%s, %s): Expected %q. Actual %q.", name, test.pattern, test.path, test.expected, actual)
+			}
+		})
+	}
+}
+
+// Verify patterns that make a backtracking matcher take exponential time are
+// matched in linear time.
+func TestExtGlobIsLinear(t *testing.T) {
+	path := strings.Repeat("a", 10000)
+
+	testIO := []struct {
+		pattern  string
+		expected bool
+	}{
+		{strings.Repeat("*(a|aa)", 20) + "b", false},
+		{"*(*(a)*(a))b", false},
+		{"!(" + strings.Repeat("*a", 20) + "b)", true},
+		{strings.Repeat("!(b)", 20), true},
+		{strings.Repeat("!(b)", 20) + "b", false},
+		{"+(!(b)|a)", true},
+	}
+
+	opts := Options{ExtGlob: true}
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			begin := time.Now()
+			if matched, _ := opts.Match(test.pattern, path); matched != test.expected {
+				t.Errorf("Test %s (%s): Expected %t. Actual %t.", name, test.pattern, test.expected, matched)
+			}
+
+			if elapsed := time.Since(begin); elapsed > 5*time.Second {
+				t.Errorf("Test %s (%s): Took %v", name, test.pattern, elapsed)
+			}
+		})
+	}
+}
+
+func FuzzExtGlob(f *testing.F) {
+	f.Add("!(*.go)", "main.go")
+	f.Add("*(a|!(b))+(c)", "abc")
+	f.Add("@(a|{b,c})?(x", "bx")
+	f.Add(`!(\`, "")
+
+	opts := Options{ExtGlob: true}
+	f.Fuzz(func(t *testing.T, pattern, path string) {
+		_, err := opts.Validate(pattern)
+		if errs := opts.ValidateAll(pattern); (err == nil) != (len(errs) == 0) {
+			t.Fatalf("Validate(%q) returned %v, but ValidateAll returned %v", pattern, err, errs)
+		}
+
+		// Match assumes the pattern is valid, but doesn't panic if it isn't
+		matched, _ := opts.Match(pattern, path)
+
+		p, err := opts.Compile(pattern)
+		if err != nil {
+			return
+		}
+
+		captures, captured := p.MatchCaptures(path)
+		if captured != matched {
+			t.Fatalf("MatchCaptures(%q, %q) returned %t, but Match returned %t", pattern, path, captured, matched)
+		}
+
+		for _, c := range captures {
+			if c.ByteStart < 0 || c.ByteStart > c.ByteEnd || c.ByteEnd > len(path) {
+				t.Fatalf("MatchCaptures(%q, %q) returned an invalid capture %+v", pattern, path, c)
+			}
+		}
+	})
+}
@@ -0,0 +1,21 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+//go:build linux
+
+package glob
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// deviceInode returns the device and inode numbers of a file, which together
+// identify it, if the file system has them.
+func deviceInode(info fs.FileInfo) (dev, ino uint64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return uint64(st.Dev), uint64(st.Ino), true
+}
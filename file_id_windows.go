@@ -0,0 +1,16 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+//go:build windows
+
+package glob
+
+import (
+	"io/fs"
+)
+
+// deviceInode returns the device and inode numbers of a file, which together
+// identify it, if the file system has them. The file information on Windows
+// doesn't, so files are identified by their resolved names instead.
+func deviceInode(info fs.FileInfo) (dev, ino uint64, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,35 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"unicode"
+)
+
+// equalFold reports whether two runes are equal under simple Unicode case
+// folding.
+func equalFold(a, b rune) bool {
+	if a == b {
+		return true
+	}
+
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inFoldedRange reports whether any case of the character, other than the
+// character itself, is in the range from lo to hi, inclusive.
+func inFoldedRange(value, lo, hi rune) bool {
+	for r := unicode.SimpleFold(value); r != value; r = unicode.SimpleFold(r) {
+		if r >= lo && r <= hi {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Verify runes are compared with simple case folding.
+func TestEqualFold(t *testing.T) {
+	testIO := []struct {
+		a, b     rune
+		expected bool
+	}{
+		{'a', 'a', true},
+		{'a', 'A', true},
+		{'A', 'a', true},
+		{'k', 'K', true}, // Kelvin sign
+		{'K', 'K', true},
+		{'s', 'ſ', true}, // long s
+		{'σ', 'ς', true},
+		{'Σ', 'ς', true},
+		{'ß', 'ẞ', true},
+		{'ǅ', 'ǆ', true},
+		{'a', 'b', false},
+		{'1', '1', true},
+		{'ß', 's', false},
+		{'/', '\\', false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if actual := equalFold(test.a, test.b); actual != test.expected {
+				t.Errorf("Test %s (%q, %q): Expected %t. Actual %t.", name, test.a, test.b, test.expected, actual)
+			}
+		})
+	}
+}
+
+// Verify a character is in a range if any other case of it is.
+func TestInFoldedRange(t *testing.T) {
+	testIO := []struct {
+		value, lo, hi rune
+		expected      bool
+	}{
+		{'k', 'A', 'Z', true},
+		{'K', 'A', 'Z', true},
+		{'K', 'a', 'z', true},
+		{'K', 'a', 'z', true},
+		{'5', 'a', 'z', false},
+		{'Ω', 'ω', 'ω', true},
+		{'[', 'A', 'Z', false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if actual := inFoldedRange(test.value, test.lo, test.hi); actual != test.expected {
+				t.Errorf("Test %s (%q, %q-%q): Expected %t. Actual %t.", name, test.value, test.lo, test.hi, test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"iter"
+	"sort"
+	"unicode/utf8"
+)
+
+// Glob returns the names of the files in the file system that match the glob
+// pattern, in lexical order. The names are slash-separated paths, as they are
+// in the io/fs package, so "src/**/*.go" matches "src/a/x.go" and
+// "src/a/b/x.go". A directory also matches a glob pattern that ends with a
+// path separator if its name followed by '/' does, so "*/" matches the
+// directories in the root, but no other files. The root itself, ".", never
+// matches.
+//
+// Glob starts walking the file system in the base directory of the glob
+// pattern (see SplitPattern), and it doesn't read directories that nothing
+// inside of could match (see CouldMatchUnder), so "docs/**/*.md" only reads
+// "docs" and the directories in it. If the base directory is a symbolic link,
+// Glob follows it, but it doesn't follow the links inside of it, which match
+// as files do. Glob returns an error if the glob pattern is invalid, or if
+// reading a directory fails for any reason except that it doesn't exist.
+func Glob(fsys fs.FS, pattern string) ([]string, error) {
+	return Options{}.Glob(fsys, pattern)
+}
+
+// Glob is like the package-level Glob function, but it matches names using
+// these options, except that the path separator is always '/'. If the options
+// fold case, the walk starts at the root of the file system, since the base
+// directory may have names that differ in case.
+func (o Options) Glob(fsys fs.FS, pattern string) ([]string, error) {
+	var matches []string
+	err := o.GlobWalk(context.Background(), fsys, pattern, func(name string, _ fs.DirEntry) error {
+		matches = append(matches, name)
+		return nil
+	})
+
+	sort.Strings(matches)
+	return matches, err
+}
+
+// GlobWalk is like Glob, but it calls fn for each file or directory that
+// matches the glob pattern as soon as it's found, instead of returning all of
+// the names at the end, so it can walk file systems too large to hold every
+// match in memory. The matches are found in the order fs.WalkDir visits them.
+//
+// If fn returns fs.SkipDir for a directory, GlobWalk doesn't walk the
+// directory, and if it returns fs.SkipDir for a file, GlobWalk skips the rest
+// of the directory the file is in. If it returns fs.SkipAll, GlobWalk stops
+// and returns nil. If it returns any other error, GlobWalk stops and returns
+// that error. GlobWalk also stops if the context is canceled, and returns the
+// context's error.
+func GlobWalk(ctx context.Context, fsys fs.FS, pattern string, fn func(name string, d fs.DirEntry) error) error {
+	return Options{}.GlobWalk(ctx, fsys, pattern, fn)
+}
+
+// GlobWalk is like the package-level GlobWalk function, but it matches names
+// using these options, like Options.Glob.
+func (o Options) GlobWalk(ctx context.Context, fsys fs.FS, pattern string, fn func(name string, d fs.DirEntry) error) error {
+	// paths in an fs.FS always use '/' as their path separator
+	o.Separator = '/'
+	p, err := o.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	err = p.walkFS(ctx, fsys, fn)
+	if err == fs.SkipAll || err == fs.SkipDir {
+		return nil
+	}
+
+	return err
+}
+
+// GlobSeq returns an iterator over the names GlobWalk finds, for use in a
+// range loop. If GlobWalk fails, the last pair has the error and an empty
+// name. Breaking out of the loop stops the walk.
+//
+//	for name, err := range glob.GlobSeq(ctx, os.DirFS("."), "**/*.go") {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(name)
+//	}
+func GlobSeq(ctx context.Context, fsys fs.FS, pattern string) iter.Seq2[string, error] {
+	return Options{}.GlobSeq(ctx, fsys, pattern)
+}
+
+// GlobSeq is like the package-level GlobSeq function, but it matches names
+// using these options, like Options.Glob.
+func (o Options) GlobSeq(ctx context.Context, fsys fs.FS, pattern string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		err := o.GlobWalk(ctx, fsys, pattern, func(name string, _ fs.DirEntry) error {
+			if !yield(name, nil) {
+				return fs.SkipAll
+			}
+			return nil
+		})
+
+		if err != nil {
+			yield("", err)
+		}
+	}
+}
+
+// walkFS walks the file system from the base directory of the glob pattern,
+// and calls found for each file or directory that matches it, stopping if it
+// returns an error other than fs.SkipDir, or if the context is canceled.
+func (p *Pattern) walkFS(ctx context.Context, fsys fs.FS, found func(name string, d fs.DirEntry) error) error {
+	base, match, ok, err := p.globBase(fsys)
+	if !ok {
+		return err
+	}
+
+	if match != nil {
+		if err := found(base, match); err == fs.SkipDir {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if !p.CouldMatchUnder(base) {
+		return nil
+	}
+
+	return p.walkDir(ctx, fsys, base, found)
+}
+
+// globBase returns the directory in the file system to start walking from,
+// and, if the glob pattern matches the directory itself, its entry. It returns
+// false if nothing in the file system can match the glob pattern, or if the
+// directory can't be read.
+func (p *Pattern) globBase(fsys fs.FS) (base string, match fs.DirEntry, ok bool, err error) {
+	base = "."
+	if !p.config.foldCase {
+		base, _ = p.config.splitPattern(p.source)
+	}
+
+	if !fs.ValidPath(base) {
+		// the names in the file system never start with a '/', nor have
+		// names such as ".." in them, so nothing matches
+		return base, nil, false, nil
+	}
+
+	if base == "." {
+		return base, nil, true, nil
+	}
+
+	info, err := fs.Stat(fsys, base)
+	if errors.Is(err, fs.ErrNotExist) {
+		return base, nil, false, nil
+	} else if err != nil {
+		return base, nil, false, err
+	}
+
+	if !info.IsDir() {
+		// the glob pattern only matches names inside the base
+		return base, nil, false, nil
+	}
+
+	if p.matchEntry(base, true) {
+		match = fs.FileInfoToDirEntry(info)
+	}
+
+	return base, match, true, nil
+}
+
+// walkDir calls found for each file or directory in the directory that
+// matches the glob pattern, and walks each subdirectory that something inside
+// of could match, unless found returns fs.SkipDir for it. If found returns
+// fs.SkipDir for a file, it skips the rest of the directory.
+func (p *Pattern) walkDir(ctx context.Context, fsys fs.FS, dir string, found func(name string, d fs.DirEntry) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		// the directory was removed after its parent was read
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, d := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		name := joinName(dir, d.Name())
+
+		if p.matchEntry(name, d.IsDir()) {
+			if err := found(name, d); err == fs.SkipDir && d.IsDir() {
+				continue
+			} else if err == fs.SkipDir {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if d.IsDir() && p.CouldMatchUnder(name) {
+			if err := p.walkDir(ctx, fsys, name, found); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchEntry returns true if the name of a file matches the glob pattern. A
+// directory also matches a glob pattern that ends with a path separator if
+// its name followed by a '/' does.
+func (p *Pattern) matchEntry(name string, isDir bool) bool {
+	if matched, _ := p.Match(name); matched {
+		return true
+	}
+
+	if r, _ := utf8.DecodeLastRuneInString(p.source); isDir && (r == GlobSeparator || r == p.config.globSeparator) {
+		matched, _ := p.Match(name + "/")
+		return matched
+	}
+
+	return false
+}
+
+// joinName returns the name of a file in a directory of the file system.
+func joinName(dir, name string) string {
+	if dir == "." {
+		return name
+	}
+
+	return dir + "/" + name
+}
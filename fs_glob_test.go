@@ -0,0 +1,390 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testFS is a small tree of files for testing Glob.
+var testFS = fstest.MapFS{
+	"README.md":                {},
+	"go.mod":                   {},
+	"main.go":                  {},
+	".env":                     {},
+	"docs/index.md":            {},
+	"docs/guide/intro.md":      {},
+	"docs/guide/img/a.png":     {},
+	"src/main.go":              {},
+	"src/main_test.go":         {},
+	"src/a/b/x.go":             {},
+	"src/a/b/y.txt":            {},
+	"src/internal/z.go":        {},
+	"src/.git/config":          {},
+	"src/.git/hooks/x.go":      {},
+	"vendor/lib/lib.go":        {},
+	"vendor/lib/docs/index.md": {},
+	"a*b/c.go":                 {},
+	"empty":                    {Mode: fs.ModeDir},
+	"日本/語.txt":                 {},
+}
+
+func TestGlob(t *testing.T) {
+	testIO := []struct {
+		opts     Options
+		pattern  string
+		expected []string
+	}{
+		{Options{}, "*.go", []string{"main.go"}},
+		{Options{}, "*", []string{".env", "README.md", "a*b", "docs", "empty", "go.mod", "main.go", "src", "vendor", "日本"}},
+		{Options{}, "src/*.go", []string{"src/main.go", "src/main_test.go"}},
+		{Options{}, "src/**/*.go", []string{"src/.git/hooks/x.go", "src/a/b/x.go", "src/internal/z.go"}},
+		{Options{}, "**/*.md", []string{"docs/guide/intro.md", "docs/index.md", "vendor/lib/docs/index.md"}},
+		{Options{}, "**.md", []string{"README.md", "docs/guide/intro.md", "docs/index.md", "vendor/lib/docs/index.md"}},
+		{Options{}, "docs/**/*.md", []string{"docs/guide/intro.md"}},
+		{Options{}, "docs/**.md", []string{"docs/guide/intro.md", "docs/index.md"}},
+		{Options{}, "docs/**", []string{"docs/guide", "docs/guide/img", "docs/guide/img/a.png", "docs/guide/intro.md", "docs/index.md"}},
+		{Options{}, "docs/*", []string{"docs/guide", "docs/index.md"}},
+		{Options{}, "*/", []string{"a*b", "docs", "empty", "src", "vendor", "日本"}},
+		{Options{}, "src/**/", []string{"src/.git", "src/.git/hooks", "src/a", "src/a/b", "src/internal"}},
+		{Options{}, "src/a/b/x.go", []string{"src/a/b/x.go"}},
+		{Options{}, "src/a/b", []string{"src/a/b"}},
+		{Options{}, "src/a/b/", []string{"src/a/b"}},
+		{Options{}, "src/a/b/x.go/*", nil},
+		{Options{}, "src/nothing/*", nil},
+		{Options{}, "nothing", nil},
+		{Options{}, "", nil},
+		{Options{}, "/src/*", nil},
+		{Options{}, "../src/*", nil},
+		{Options{}, "./src/*", nil},
+		{Options{}, `a\*b/*`, []string{"a*b/c.go"}},
+		{Options{}, "a*b/*", []string{"a*b/c.go"}},
+		{Options{}, "src/[ai]*/*.go", []string{"src/internal/z.go"}},
+		{Options{Braces: true}, "src/{a/b,internal}/*.go", []string{"src/a/b/x.go", "src/internal/z.go"}},
+		{Options{}, "日本/*", []string{"日本/語.txt"}},
+		{Options{}, "empty/**", nil},
+		{Options{}, "empty/", []string{"empty"}},
+		{Options{}, "main.go/", nil},
+
+		// '*' never crosses a '/', and '**' matches any number of directories
+		{Options{}, "src/*/*.go", []string{"src/internal/z.go"}},
+		{Options{}, "src*", []string{"src"}},
+		{Options{}, "src**", []string{"src", "src/.git", "src/.git/config", "src/.git/hooks", "src/.git/hooks/x.go", "src/a", "src/a/b", "src/a/b/x.go", "src/a/b/y.txt", "src/internal", "src/internal/z.go", "src/main.go", "src/main_test.go"}},
+
+		// options
+		{Options{NoDot: true}, "*", []string{"README.md", "a*b", "docs", "empty", "go.mod", "main.go", "src", "vendor", "日本"}},
+		{Options{NoHiddenDirs: true}, "src/**/*.go", []string{"src/a/b/x.go", "src/internal/z.go"}},
+		{Options{ExtGlob: true}, "!(vendor)/**.md", []string{"docs/guide/intro.md", "docs/index.md"}},
+		{Options{FoldCase: true}, "SRC/*.GO", []string{"src/main.go", "src/main_test.go"}},
+		{Options{Dialect: WindowsDialect, Escape: '`'}, `docs\*`, []string{"docs/guide", "docs/index.md"}},
+		{Options{Separator: '\\'}, "docs/*", []string{"docs/guide", "docs/index.md"}},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			matches, err := test.opts.Glob(testFS, test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if fmt.Sprint(matches) != fmt.Sprint(test.expected) {
+				t.Errorf("Test %s (%s): Expected %q. Actual %q.", name, test.pattern, test.expected, matches)
+			}
+		})
+	}
+}
+
+// recordingFS records each directory that is read.
+type recordingFS struct {
+	fs.FS
+	dirs []string
+}
+
+func (r *recordingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	r.dirs = append(r.dirs, name)
+	return fs.ReadDir(r.FS, name)
+}
+
+// Verify the walk starts in the base directory, and skips directories that
+// nothing inside of could match.
+func TestGlobPrunes(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"docs/**/*.md", []string{"docs", "docs/guide", "docs/guide/img"}},
+		{"src/*.go", []string{"src"}},
+		{"src/a/b/*", []string{"src/a/b"}},
+		{"*/lib/*.go", []string{".", "a*b", "docs", "empty", "src", "vendor", "vendor/lib", "日本"}},
+		{"*.go", []string{"."}},
+		{"nothing/*", nil},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			fsys := &recordingFS{FS: testFS}
+			if _, err := Glob(fsys, test.pattern); err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			sort.Strings(fsys.dirs)
+			if fmt.Sprint(fsys.dirs) != fmt.Sprint(test.expected) {
+				t.Errorf("Test %s (%s): Expected to read %q. Actual %q.", name, test.pattern, test.expected, fsys.dirs)
+			}
+		})
+	}
+}
+
+// failingFS fails to read one directory.
+type failingFS struct {
+	fs.FS
+	dir string
+	err error
+}
+
+func (f failingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == f.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: f.err}
+	}
+	return fs.ReadDir(f.FS, name)
+}
+
+// Verify invalid glob patterns and errors reading directories are reported,
+// except for directories that don't exist.
+func TestGlobErrors(t *testing.T) {
+	if _, err := Glob(testFS, "src/[a"); !errors.Is(err, ErrGlobTruncated) {
+		t.Errorf("Expected %v. Actual %v.", ErrGlobTruncated, err)
+	}
+
+	matches, err := Glob(failingFS{FS: testFS, dir: "src/a", err: fs.ErrPermission}, "src/**/*.go")
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("Expected %v. Actual %v.", fs.ErrPermission, err)
+	}
+	if len(matches) == 0 {
+		t.Errorf("Expected the matches found before the error. Actual %q.", matches)
+	}
+
+	matches, err = Glob(failingFS{FS: testFS, dir: "src/a", err: fs.ErrNotExist}, "src/**/*.go")
+	if err != nil || len(matches) != 2 {
+		t.Errorf("Expected 2 matches and no error. Actual %q, %v.", matches, err)
+	}
+
+	if matches, err := Glob(failingFS{FS: testFS, dir: "vendor", err: fs.ErrPermission}, "docs/*"); err != nil || len(matches) != 2 {
+		t.Errorf("Expected 2 matches and no error. Actual %q, %v.", matches, err)
+	}
+}
+
+// Verify Glob follows a base directory that's a symbolic link, but not the
+// links inside of it.
+func TestGlobSymlinks(t *testing.T) {
+	fsys := fstest.MapFS{
+		"real/a.go":     {},
+		"real/sub/b.go": {},
+		"real/link":     {Data: []byte("sub"), Mode: fs.ModeSymlink},
+		"base":          {Data: []byte("real"), Mode: fs.ModeSymlink},
+	}
+
+	expected := []string{"base/a.go", "base/link", "base/sub", "base/sub/b.go"}
+	if matches, err := Glob(fsys, "base/**"); err != nil || fmt.Sprint(matches) != fmt.Sprint(expected) {
+		t.Errorf("Expected %q. Actual %q, %v.", expected, matches, err)
+	}
+}
+
+// walkMatches returns the names in the file system that the glob pattern
+// matches, by matching every one of them.
+func walkMatches(t *testing.T, fsys fs.FS, p *Pattern) []string {
+	var matches []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && p.matchEntry(name, d.IsDir()) {
+			matches = append(matches, name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	sort.Strings(matches)
+	return matches
+}
+
+// FuzzGlob checks that Glob finds the same names in a file system as matching
+// every one of them does.
+func FuzzGlob(f *testing.F) {
+	f.Add("src/**/*.go")
+	f.Add("*/*/")
+	f.Add("{docs,vendor/lib}/**/index.md")
+	f.Add("s*/a*/**")
+
+	opts := Options{Separator: '/', ExtGlob: true}
+	f.Fuzz(func(t *testing.T, pattern string) {
+		p, err := opts.Compile(pattern)
+		if err != nil || strings.Count(pattern, "{") > 4 {
+			return
+		}
+
+		matches, err := opts.Glob(testFS, pattern)
+		if err != nil {
+			t.Fatalf("Glob(%q) returned an unexpected error %v", pattern, err)
+		}
+
+		if expected := walkMatches(t, testFS, p); fmt.Sprint(matches) != fmt.Sprint(expected) {
+			t.Fatalf("Glob(%q) returned %q, but matching each name returned %q", pattern, matches, expected)
+		}
+	})
+}
+
+// Verify GlobWalk finds the matches in the order fs.WalkDir visits them, and
+// skips directories, the rest of a directory, or everything, as fn asks.
+func TestGlobWalk(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		skip     string // the match for which fn returns err
+		err      error
+		expected []string
+	}{
+		{"src/**", "", nil, []string{"src/.git", "src/.git/config", "src/.git/hooks", "src/.git/hooks/x.go", "src/a", "src/a/b", "src/a/b/x.go", "src/a/b/y.txt", "src/internal", "src/internal/z.go", "src/main.go", "src/main_test.go"}},
+		{"**.md", "", nil, []string{"README.md", "docs/guide/intro.md", "docs/index.md", "vendor/lib/docs/index.md"}},
+		{"src/**", "src/a", fs.SkipDir, []string{"src/.git", "src/.git/config", "src/.git/hooks", "src/.git/hooks/x.go", "src/a", "src/internal", "src/internal/z.go", "src/main.go", "src/main_test.go"}},
+		{"src/**", "src/.git/config", fs.SkipDir, []string{"src/.git", "src/.git/config", "src/a", "src/a/b", "src/a/b/x.go", "src/a/b/y.txt", "src/internal", "src/internal/z.go", "src/main.go", "src/main_test.go"}},
+		{"src/**", "src/a/b", fs.SkipAll, []string{"src/.git", "src/.git/config", "src/.git/hooks", "src/.git/hooks/x.go", "src/a", "src/a/b"}},
+		{"src/a/", "src/a", fs.SkipDir, []string{"src/a"}},
+		{"src**", "src", fs.SkipDir, []string{"src"}},
+		{"src**", "src", fs.SkipAll, []string{"src"}},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			var matches []string
+			err := GlobWalk(context.Background(), testFS, test.pattern, func(match string, d fs.DirEntry) error {
+				if _, err := fs.Stat(testFS, match); err != nil || d.Name() != match[strings.LastIndex(match, "/")+1:] {
+					t.Errorf("Test %s (%s): Unexpected entry %q for %q", name, test.pattern, d.Name(), match)
+				}
+
+				matches = append(matches, match)
+				if match == test.skip {
+					return test.err
+				}
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if fmt.Sprint(matches) != fmt.Sprint(test.expected) {
+				t.Errorf("Test %s (%s): Expected %q. Actual %q.", name, test.pattern, test.expected, matches)
+			}
+		})
+	}
+}
+
+// Verify GlobWalk stops with the error fn returns, or when the context is
+// canceled.
+func TestGlobWalkErrors(t *testing.T) {
+	errStop := errors.New("stop")
+	var count int
+	err := GlobWalk(context.Background(), testFS, "**", func(string, fs.DirEntry) error {
+		count++
+		if count == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || count != 3 {
+		t.Errorf("Expected %v after 3 matches. Actual %v after %d.", errStop, err, count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	count = 0
+	err = GlobWalk(ctx, testFS, "**", func(string, fs.DirEntry) error {
+		count++
+		if count == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || count != 3 {
+		t.Errorf("Expected %v after 3 matches. Actual %v after %d.", context.Canceled, err, count)
+	}
+
+	fsys := &recordingFS{FS: testFS}
+	err = GlobWalk(ctx, fsys, "**", func(string, fs.DirEntry) error {
+		t.Error("Expected no matches after the context was canceled")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || len(fsys.dirs) != 0 {
+		t.Errorf("Expected %v without reading a directory. Actual %v after reading %q.", context.Canceled, err, fsys.dirs)
+	}
+
+	if err := GlobWalk(context.Background(), testFS, "[", nil); !errors.Is(err, ErrGlobTruncated) {
+		t.Errorf("Expected %v. Actual %v.", ErrGlobTruncated, err)
+	}
+}
+
+func TestGlobSeq(t *testing.T) {
+	var matches []string
+	for name, err := range GlobSeq(context.Background(), testFS, "docs/**") {
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		matches = append(matches, name)
+	}
+
+	expected := []string{"docs/guide", "docs/guide/img", "docs/guide/img/a.png", "docs/guide/intro.md", "docs/index.md"}
+	if fmt.Sprint(matches) != fmt.Sprint(expected) {
+		t.Errorf("Expected %q. Actual %q.", expected, matches)
+	}
+
+	// breaking out of the loop stops the walk
+	fsys := &recordingFS{FS: testFS}
+	for name := range (Options{}).GlobSeq(context.Background(), fsys, "**") {
+		if name == "docs" {
+			break
+		}
+	}
+	if fmt.Sprint(fsys.dirs) != "[. a*b]" {
+		t.Errorf("Expected to read only the root and \"a*b\". Actual %q.", fsys.dirs)
+	}
+
+	// an error is the last pair
+	var errs []error
+	for name, err := range GlobSeq(context.Background(), failingFS{FS: testFS, dir: "docs/guide", err: fs.ErrPermission}, "docs/**") {
+		if err != nil {
+			errs = append(errs, err)
+			if name != "" {
+				t.Errorf("Expected an empty name with the error. Actual %q.", name)
+			}
+		} else if len(errs) > 0 {
+			t.Errorf("Expected the error to be last. Actual %q after it.", name)
+		}
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], fs.ErrPermission) {
+		t.Errorf("Expected %v. Actual %v.", fs.ErrPermission, errs)
+	}
+
+	for _, err := range GlobSeq(context.Background(), testFS, "{a") {
+		if !errors.Is(err, ErrGlobUnclosedBrace) {
+			t.Errorf("Expected %v. Actual %v.", ErrGlobUnclosedBrace, err)
+		}
+	}
+}
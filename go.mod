@@ -2,4 +2,4 @@
 
 module dbc60/goglob
 
-go 1.20
+go 1.25
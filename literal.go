@@ -0,0 +1,168 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+// SplitPattern splits a glob pattern into the longest directory that every
+// path it matches is in, and the rest of the glob pattern, which matches
+// paths relative to that directory. For example, it splits
+// "src/internal/**/*.go" into "src/internal" and "**/*.go". The base has no
+// wildcards, classes, brace expressions or escape characters, and '/'
+// separates its names. If the glob pattern starts with a path separator, so
+// does the base, and any separators that follow that one start the rest, so
+// "//*" splits into "/" and "/*". If nothing before the first wildcard names a
+// directory other than ".", the base is "." and the rest is the entire glob
+// pattern. Like Match, it assumes the glob pattern is valid.
+func SplitPattern(pattern string) (base, rest string) {
+	return defaultConfig.splitPattern(pattern)
+}
+
+// SplitPattern is like the package-level SplitPattern function, but it uses
+// these options to decide what is a wildcard, an escape character or a path
+// separator. In the Windows dialect, a base that's a drive designator ends
+// with '/', such as "C:/", so it names the root of the drive.
+func (o Options) SplitPattern(pattern string) (base, rest string) {
+	return o.config().splitPattern(pattern)
+}
+
+// LiteralPrefix returns the literal characters that every path matching the
+// compiled glob pattern starts with, with escape characters removed and '/'
+// for each path separator. It returns true if the prefix is the entire glob
+// pattern, so the only path it matches is the prefix itself. If the glob
+// pattern folds case, the paths start with the prefix without regard to case.
+func (p *Pattern) LiteralPrefix() (prefix string, complete bool) {
+	pattern := p.config.runes(p.source)
+	literal, n := p.config.literal(pattern)
+	return encodeRunes(literal), n == len(pattern)
+}
+
+// LiteralSuffix returns the literal characters that every path matching the
+// compiled glob pattern ends with, after its last wildcard, class, brace
+// expression or extended glob, like LiteralPrefix. A glob pattern without any
+// of them is its own suffix.
+func (p *Pattern) LiteralSuffix() string {
+	pattern := p.config.runes(p.source)
+
+	var start int
+	for i := 0; i < len(pattern); {
+		_, size, ok := p.config.literalAt(pattern, i)
+		i += size
+		if !ok {
+			start = i
+		}
+	}
+
+	literal, _ := p.config.literal(pattern[start:])
+	return encodeRunes(literal)
+}
+
+// splitPattern splits a glob pattern at the path separator that ends the last
+// directory name before its first wildcard, or before its last name if the
+// glob pattern has no wildcards.
+func (c *config) splitPattern(pattern string) (base, rest string) {
+	runes := c.runes(pattern)
+	literal, n := c.literal(runes)
+
+	// a drive designator, such as "C:", is part of the root
+	var root int
+	if c.windows && len(runes) > 1 && c.isDriveColon(runes, 1) {
+		root = 2
+	}
+
+	// find the last path separator in the literal prefix, and the offset of
+	// the character it matches in the literal characters. If the entire glob
+	// pattern is literal, a separator at its end only says that the last name
+	// is a directory, so it doesn't count. The separators that follow the
+	// first one at the root are empty names in the rest of the glob pattern,
+	// so the base is just the root, and the two put together are the glob
+	// pattern again.
+	separator, end := -1, -1
+	atRoot := true
+	for i, length := 0, 0; i < n; length++ {
+		r, size, _ := c.literalAt(runes, i)
+		isSeparator := r == GlobSeparator && size == 1
+		if isSeparator && i+1 < len(runes) && !(atRoot && separator >= 0) {
+			separator, end = i, length
+		}
+		atRoot = atRoot && (isSeparator || i < root)
+		i += size
+	}
+
+	if separator < 0 {
+		return ".", pattern
+	}
+
+	// a base of "." would mean the glob pattern has none, so "./" stays in
+	// the rest
+	base = encodeRunes(literal[:end])
+	if base == "." {
+		return ".", pattern
+	}
+
+	switch {
+	case end == 0:
+		base = string(GlobSeparator)
+	case c.windows && separator == 2 && c.isDriveColon(runes, 1):
+		base += string(GlobSeparator)
+	}
+
+	return base, pattern[c.byteLength(pattern, separator+1):]
+}
+
+// literal returns the literal characters at the start of the glob pattern,
+// with escape characters removed, and the number of runes of the glob pattern
+// they come from.
+func (c *config) literal(pattern []rune) ([]rune, int) {
+	var literal []rune
+
+	var i int
+	for i < len(pattern) {
+		r, size, ok := c.literalAt(pattern, i)
+		if !ok {
+			break
+		}
+		literal = append(literal, r)
+		i += size
+	}
+
+	return literal, i
+}
+
+// literalAt returns the character that the token at offset i in the glob
+// pattern matches, and the number of runes in the token. It returns false if
+// the token isn't a literal character, but a wildcard, a class, a brace
+// expression, an extended glob, or a trailing escape character, which matches
+// nothing. A path separator is '/', whichever separator the glob pattern uses.
+func (c *config) literalAt(pattern []rune, i int) (rune, int, bool) {
+	token := pattern[i]
+	switch {
+	case token == c.escape:
+		if i+1 == len(pattern) {
+			return 0, 1, false
+		}
+		return pattern[i+1], 2, true
+	case c.isExtGlob(pattern, i):
+		return 0, c.extGlobEnd(pattern[i:]) + 1, false
+	case token == '[':
+		return 0, c.classLength(pattern[i:]), false
+	case token == '?' || token == '*':
+		return 0, 1, false
+	case token == '{' && c.braces && c.isBraceExpression(pattern[i:]):
+		end, _ := c.braceEnd(pattern[i:])
+		return 0, end + 1, false
+	case token == GlobSeparator || token == c.globSeparator:
+		return GlobSeparator, 1, true
+	}
+
+	return token, 1, true
+}
+
+// byteLength returns the number of bytes in the first n characters of s.
+func (c *config) byteLength(s string, n int) int {
+	var length int
+	for ; n > 0 && length < len(s); n-- {
+		_, size := c.decode(s[length:])
+		length += size
+	}
+
+	return length
+}
@@ -0,0 +1,257 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitPattern(t *testing.T) {
+	testIO := []struct {
+		opts    Options
+		pattern string
+		base    string
+		rest    string
+	}{
+		{Options{}, "src/internal/**/*.go", "src/internal", "**/*.go"},
+		{Options{}, "src/*.go", "src", "*.go"},
+		{Options{}, "src/a*/b", "src", "a*/b"},
+		{Options{}, "*.go", ".", "*.go"},
+		{Options{}, "**", ".", "**"},
+		{Options{}, "", ".", ""},
+		{Options{}, "a", ".", "a"},
+		{Options{}, "a/b/c", "a/b", "c"},
+		{Options{}, "a/b/", "a", "b/"},
+		{Options{}, "/", ".", "/"},
+		{Options{}, "/usr/lib/*.so", "/usr/lib", "*.so"},
+		{Options{}, "/*.txt", "/", "*.txt"},
+		{Options{}, "/etc/passwd", "/etc", "passwd"},
+		{Options{}, "//*", "/", "/*"},
+		{Options{}, "///*", "/", "//*"},
+		{Options{}, "//a/*", "//a", "*"},
+		{Options{}, "a//b/*", "a//b", "*"},
+		{Options{}, "./*", ".", "./*"},
+		{Options{}, "./a/*", "./a", "*"},
+
+		// escaped characters are literal, and unescaped in the base
+		{Options{}, `a\*b/c/*.go`, "a*b/c", "*.go"},
+		{Options{}, `a\?/\[x]/?`, "a?/[x]", "?"},
+		{Options{}, `a\\b/*`, `a\b`, "*"},
+		{Options{}, `a/b\*`, "a", `b\*`},
+		{Options{Escape: '`'}, "a`*/*", "a*", "*"},
+
+		// classes, brace expressions and extended globs are wildcards, and
+		// a '/' inside of one doesn't end a directory name
+		{Options{}, "a/[bc]/d", "a", "[bc]/d"},
+		{Options{}, "a/b[/]c/d", "a", "b[/]c/d"},
+		{Options{}, "a/[*]/b", "a", "[*]/b"},
+		{Options{Braces: true}, "src/{cmd,pkg}/*.go", "src", "{cmd,pkg}/*.go"},
+		{Options{Braces: true}, "{a/b,c}/d", ".", "{a/b,c}/d"},
+		{Options{Braces: true}, "{a}/b/*", "{a}/b", "*"},
+		{Options{}, "src/{cmd,pkg}/*.go", "src/{cmd,pkg}", "*.go"},
+		{Options{ExtGlob: true}, "src/@(a|b)/c", "src", "@(a|b)/c"},
+		{Options{}, "src/@(a|b)/c", "src/@(a|b)", "c"},
+		{Options{ExtGlob: true}, "src/x(1)/*", "src/x(1)", "*"},
+
+		// non-ASCII names keep their bytes, and the rest is sliced from the
+		// original glob pattern
+		{Options{}, "日本/語/*.txt", "日本/語", "*.txt"},
+		{Options{}, "a/\xff/*", "a/\uFFFD", "*"},
+		{Options{ExactBytes: true}, "a/\xff/*", "a/\xff", "*"},
+		{Options{}, "a/b\xff*", "a", "b\xff*"},
+
+		// the Windows dialect
+		{Options{Dialect: WindowsDialect}, "C:/Users/*/*.txt", "C:/Users", "*/*.txt"},
+		{Options{Dialect: WindowsDialect}, "C:/*.txt", "C:/", "*.txt"},
+		{Options{Dialect: WindowsDialect}, "C:*.txt", ".", "C:*.txt"},
+		{Options{Dialect: WindowsDialect}, "C://*.txt", "C:/", "/*.txt"},
+		{Options{Dialect: WindowsDialect}, "?:/x/*", ".", "?:/x/*"},
+		{Options{Dialect: WindowsDialect}, "//server/share/*", "//server/share", "*"},
+		{Options{Dialect: WindowsDialect, Escape: '`'}, `C:\Users\*\*.txt`, "C:/Users", `*\*.txt`},
+		{Options{Dialect: WindowsDialect, Escape: '`'}, `\\server\share\a`, "//server/share", "a"},
+		{Options{Dialect: WindowsDialect, Escape: '`'}, "C:\\`[x]\\*", "C:/[x]", "*"},
+		{Options{Dialect: UnixDialect}, "C:/*.txt", "C:", "*.txt"},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if _, err := test.opts.Validate(test.pattern); err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			base, rest := test.opts.SplitPattern(test.pattern)
+			if base != test.base || rest != test.rest {
+				t.Errorf("Test %s (%s): Expected (%q, %q). Actual (%q, %q).", name, test.pattern, test.base, test.rest, base, rest)
+			}
+		})
+	}
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	testIO := []struct {
+		opts     Options
+		pattern  string
+		prefix   string
+		complete bool
+	}{
+		{Options{}, "", "", true},
+		{Options{}, "abc", "abc", true},
+		{Options{}, "src/*.go", "src/", false},
+		{Options{}, "*.go", "", false},
+		{Options{}, "a?c", "a", false},
+		{Options{}, "ab[cd]", "ab", false},
+		{Options{Braces: true}, "ab{c,d}", "ab", false},
+		{Options{Braces: true}, "ab{c}", "ab{c}", true},
+		{Options{}, "ab{c,d}", "ab{c,d}", true},
+		{Options{ExtGlob: true}, "ab@(c|d)", "ab", false},
+		{Options{ExtGlob: true}, "ab(c|d)", "ab(c|d)", true},
+		{Options{}, `a\*b\?c`, "a*b?c", true},
+		{Options{}, `a\*b*`, "a*b", false},
+		{Options{}, `a\\`, `a\`, true},
+		{Options{Escape: '`', LenientEscapes: true}, "`a\\", `a\`, true},
+		{Options{Dialect: WindowsDialect, Escape: '`'}, `src\x\*`, "src/x/", false},
+		{Options{FoldCase: true}, "Src/*", "Src/", false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p, err := test.opts.Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			prefix, complete := p.LiteralPrefix()
+			if prefix != test.prefix || complete != test.complete {
+				t.Errorf("Test %s (%s): Expected (%q, %t). Actual (%q, %t).", name, test.pattern, test.prefix, test.complete, prefix, complete)
+			}
+		})
+	}
+}
+
+func TestLiteralSuffix(t *testing.T) {
+	testIO := []struct {
+		opts    Options
+		pattern string
+		suffix  string
+	}{
+		{Options{}, "", ""},
+		{Options{}, "abc", "abc"},
+		{Options{}, "**/*.go", ".go"},
+		{Options{}, "*", ""},
+		{Options{}, "a*b*", ""},
+		{Options{}, "a?c", "c"},
+		{Options{}, "*[.]go", "go"},
+		{Options{}, "*[]]x", "x"},
+		{Options{Braces: true}, "*{a,b}.txt", ".txt"},
+		{Options{Braces: true}, "*{a,{b,c}}x", "x"},
+		{Options{ExtGlob: true}, "*@(a|+(b)).txt", ".txt"},
+		{Options{ExtGlob: true}, "*@(a|[)]).txt", ".txt"},
+		{Options{}, `*\*.go`, "*.go"},
+		{Options{}, `*\\`, `\`},
+		{Options{}, "*/x/y", "/x/y"},
+		{Options{Dialect: WindowsDialect, Escape: '`'}, `**\x\y`, "/x/y"},
+		{Options{ExactBytes: true}, "*\xff", "\xff"},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p, err := test.opts.Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if suffix := p.LiteralSuffix(); suffix != test.suffix {
+				t.Errorf("Test %s (%s): Expected %q. Actual %q.", name, test.pattern, test.suffix, suffix)
+			}
+		})
+	}
+}
+
+func TestByteLength(t *testing.T) {
+	testIO := []struct {
+		s        string
+		n        int
+		expected int
+	}{
+		{"abc", 0, 0},
+		{"abc", 2, 2},
+		{"abc", 5, 3},
+		{"日本語", 2, 6},
+		{"a\xffb", 2, 2},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if actual := defaultConfig.byteLength(test.s, test.n); actual != test.expected {
+				t.Errorf("Test %s (%q, %d): Expected %d. Actual %d.", name, test.s, test.n, test.expected, actual)
+			}
+		})
+	}
+}
+
+// FuzzSplitPattern checks that a path matches a glob pattern exactly when it's
+// in the base directory and the rest of the path matches the rest of the glob
+// pattern, and that the path has the literal prefix and suffix.
+func FuzzSplitPattern(f *testing.F) {
+	f.Add("src/**/*.go", "src/a/b.go")
+	f.Add(`a\*/[/]/b`, "a*///b")
+	f.Add("/x/{a,b/c}/*", "/x/b/c/d")
+	f.Add("a/b/", "a/b/")
+
+	opts := Options{Dialect: UnixDialect, Braces: true, ExtGlob: true}
+	f.Fuzz(func(t *testing.T, pattern, path string) {
+		if !utf8.ValidString(pattern) || !utf8.ValidString(path) {
+			return
+		}
+
+		p, err := opts.Compile(pattern)
+		if err != nil {
+			return
+		}
+
+		matched, _ := p.Match(path)
+
+		base, rest := opts.SplitPattern(pattern)
+		var relative string
+		var inBase bool
+		switch base {
+		case ".":
+			relative, inBase = path, true
+		case "/":
+			relative, inBase = strings.CutPrefix(path, "/")
+		default:
+			relative, inBase = strings.CutPrefix(path, base+"/")
+		}
+
+		if restMatched, _ := opts.Match(rest, relative); matched != (inBase && restMatched) {
+			t.Fatalf("Match(%q, %q) returned %t, but SplitPattern returned (%q, %q)", pattern, path, matched, base, rest)
+		}
+
+		if !matched {
+			return
+		}
+
+		if prefix, complete := p.LiteralPrefix(); !strings.HasPrefix(path, prefix) || complete && path != prefix {
+			t.Fatalf("Match(%q, %q) returned true, but LiteralPrefix returned (%q, %t)", pattern, path, prefix, complete)
+		}
+
+		if suffix := p.LiteralSuffix(); !strings.HasSuffix(path, suffix) {
+			t.Fatalf("Match(%q, %q) returned true, but LiteralSuffix returned %q", pattern, path, suffix)
+		}
+	})
+}
@@ -2,15 +2,25 @@
 
 // Package glob implements functions for matching file paths against glob
 // patterns. The Linux implementation expects directory separators to be
-// forward slashes, while the Windows implementation expects backslashes.
+// forward slashes, while the Windows implementation expects backslashes. The
+// Options type selects the separator at run time instead.
 // This is a library package meant to be a part of other programs. The main
 // point of entry is the Match function:
 //
 //	Match(patternString, pathString string) (bool, int).
+//
+// A glob pattern that is matched against many paths can be compiled once with
+// Compile or MustCompile, and the resulting Pattern used in place of Match.
+//
+// Options.Braces adds brace expressions, such as "*.{go,mod}" and
+// "file{01..12}.txt", which match any one of their alternatives. They are
+// compiled into an automaton rather than expanded, so matching them never takes
+// exponential time. Options.ExtGlob adds bash's extended globs, such as
+// "!(*_test).go", which are compiled into the same kind of automaton.
 package glob
 
 import (
-	"strings"
+	"unicode/utf8"
 )
 
 // The escape character is used to enable interpreting characters used in glob
@@ -28,6 +38,7 @@ const GlobSeparatorString = "/"
 //
 // This function may be used repeatedly to check multiple paths against a single
 // glob pattern. As such, it is assumed that the glob represents a valid pattern.
+// Use MatchE for patterns that may be invalid, such as those from user input.
 //
 // The goal of this algorithm is to use a set of glob patterns to match against
 // paths in a file system where patterns can match paths either at a fixed-depth
@@ -72,41 +83,30 @@ const GlobSeparatorString = "/"
 // the initial asterisk is the "follower".
 //
 // The asterisk matches characters in the path in a non-greedy fashion. That is,
-// it matches a character only when the follower fails to match the path either
-// before the end of the path or before the next path separator _that doesn't
-// match a literal path separator in the pattern_. Matching succeeds when the
-// follower is consumed, and fails when either the path is consumed before the
-// follower is consumed or a path separator in the path is encountered and it
-// doesn't match a literal path separator in the follower. If this is the only
-// pattern in the glob pattern string, then both the pattern and the path must
-// be completely consumed for the match to succeed.
+// it matches a character only when the follower, or a pattern after it, fails
+// to match the path. Matching fails when the asterisk would have to match a
+// path separator, or the end of the path, unless an earlier double asterisk can
+// match more of the path instead (see below). If this is the only pattern in
+// the glob pattern string, then both the pattern and the path must be
+// completely consumed for the match to succeed.
 //
 // ## Type 3 Glob Pattern, Recursive Pattern
 //
 // Type 3 glob patterns start with two asterisks (`**`) followed by a Type 1
-// pattern and zero or more Type 2 patterns. Note that three or more consecutive
-// asterisks are reduced to a single pair (`**`). A Type 3 pattern terminates
-// either at the end of the glob pattern string or the next sequence of two or
-// more asterisks in the pattern string. The double asterisk matches zero or
-// more characters _including path separators_.
-//
-// Like Type 2 patterns, Type 3 patterns matches characters in the path in a non-
-// greedy fashion. The difference is that its follower may need to be segmented
-// into two or more followers, where each segment after the first is a Type 2
-// pattern.
-//
-// The matching algorithm for a Type 3 glob pattern has two cases, the follower
-// is either a Type 1 or a Type 2 pattern. In the first case there is only one
-// segment. This case is processed similarly to the Type 2 pattern, except for
-// the fact that a path separator may always be consumed from the path before
-// the matching process restarts.
-//
-// The second case is a Type 1 segment followed by one or more Type 2 segments.
-// In this case, matching must be restarted if _any of the segments fail_. Like
-// before, the double asterisk consumes one character (which may be a path
-// separator), and matching resumes with the first segment and this new, shorter
-// path. It succeeds when all segments succeed, and fails when any segment fails
-// and the path is consumed.
+// pattern. Note that three or more consecutive asterisks are reduced to a
+// single pair (`**`). A Type 3 pattern terminates either at the end of the glob
+// pattern string or the next asterisk in the pattern string. The double
+// asterisk matches zero or more characters _including path separators_.
+//
+// Like Type 2 patterns, Type 3 patterns match characters in the path in a
+// non-greedy fashion. When a pattern after the double asterisk fails, only the
+// most recent asterisk matches one more character, and the patterns after it
+// are tried again. If that asterisk is a single one that can't match any more,
+// the most recent double asterisk matches more of the path instead. It skips
+// past the first path separator after where its follower last matched, since
+// matching any less of the path fails the same way. Nothing before the most
+// recent double asterisk is ever tried again, so the time matching takes grows
+// linearly with the length of the path.
 //
 // ## Example Glob Patterns
 //
@@ -129,78 +129,195 @@ const GlobSeparatorString = "/"
 //	          "/usr/bat/x.txt", "/usr/foo/bar/baz/file.txt",
 //			  "/usr/one/two/three/car/note.txt", and "/usr/ba世/界.txt"
 func Match(patternString, pathString string) (bool, int) {
-	var matchCount int
-	var count int
-	var matched bool
+	return newPattern(patternString, defaultConfig).Match(pathString)
+}
+
+// MatchE is like Match, but it validates the glob pattern first, so it's safe
+// to use with patterns from untrusted sources. It returns the same error as
+// Validate if the pattern is invalid. It never panics, whatever the pattern
+// and path.
+func MatchE(patternString, pathString string) (bool, int, error) {
+	return Options{}.MatchE(patternString, pathString)
+}
 
-	patternMatched := true
-
-	pattern := []rune(patternString)
-	path := []rune(pathString)
-
-	// Get the next chunk of the glob pattern, and the pattern type. Note that
-	// there can be only one chunk that is simple, and it will be the first one.
-	// If there are any subsequent chunks, they will be either one that matches
-	// any file or directory name or one that does that recursively.
-	head, tail, kind := nextPattern(pattern)
-
-	if kind == patternSimple {
-		patternMatched, matchCount = matchSimple(head, path)
-		if patternMatched {
-			path = path[matchCount:]
-			if len(tail) == 0 {
-				// no more glob patterns, so return what we have
-				matched = len(path) == 0
-				return matched, matchCount
+// Match reports whether the path is matched by the compiled glob pattern. Like
+// the package-level Match function, it returns true and the number of runes in
+// the path that were matched if the path is matched by the glob pattern or
+// false and the number of runes matched if the match failed.
+//
+// Matching doesn't allocate memory. A glob pattern that was compiled into a
+// program, because it has brace expressions or extended globs, or because of
+// the NoDot or NoHiddenDirs options, reuses the memory of earlier matches, and
+// only allocates when there's none to reuse, such as the first time. The
+// one exception is a Windows path that starts with `\\?\UNC\`, which is
+// copied to replace that prefix with `\\`.
+func (p *Pattern) Match(pathString string) (bool, int) {
+	return p.match(pathString, nil)
+}
+
+// match matches the path against the glob pattern. If starts isn't nil, it
+// must have one element for each chunk, and match records in it the rune
+// offset in the path where the head of each chunk it visits was matched. If
+// the glob pattern was compiled into a program, starts has two capture slots
+// for each capture instead. In the Windows dialect, a long-path prefix is
+// removed before the path is matched, but the counts and offsets returned
+// include it.
+func (p *Pattern) match(path string, starts []int) (bool, int) {
+	var shift int
+	if p.config.windows {
+		path, shift = trimLongPathPrefix(path)
+	}
+
+	var matched bool
+	var count int
+	if p.usesProgram(path) {
+		matched, count = p.prog.run(p.config, path, starts)
+	} else {
+		// the chunks count bytes, so turn the counts into runes
+		matched, count = p.matchChunks(path, starts)
+		for i := range starts {
+			if starts[i] >= 0 {
+				starts[i] = utf8.RuneCountInString(path[:starts[i]])
 			}
 		}
+		count = utf8.RuneCountInString(path[:count])
+	}
 
-		pattern = tail
-		head, tail, kind = nextPattern(pattern)
+	for i := range starts {
+		if starts[i] >= 0 {
+			starts[i] += shift
+		}
 	}
 
-	for kind == patternDirectory && patternMatched && (len(head) > 0 || len(tail) > 0 || len(path) > 0) {
-		if len(head) == 0 {
-			// pattern is just a "*" wildcard
-			matched = !strings.Contains(string(path), SeparatorString)
-			if matched {
-				matchCount += len(path)
-			} else {
-				matchCount += strings.Index(string(path), SeparatorString)
-			}
-			return matched, matchCount
+	return matched, count + shift
+}
+
+// matchChunks walks the chunks of the glob pattern, matching the head of each
+// one against the path in turn, and recording the byte offsets where the heads
+// were matched in starts. It returns the number of bytes matched or, if the
+// match failed, the number matched by the chunks before the '*' or '**' that
+// couldn't be matched.
+//
+// Like the glob matcher in Russ Cox's "Glob Matching Can Be Simple And Fast
+// Too", it never backtracks further than the most recent '*' and '**'. If the
+// head of a chunk, or the chunks after it, can't be matched, the '*' or '**'
+// before the head consumes one more character and the head is tried again. A
+// '*' can't consume a path separator, so when it reaches one, or the end of the
+// path, the most recent '**' is restarted instead. It skips past the first path
+// separator after where its head was last matched, since the chunks would fail
+// the same way anywhere before it. That holds because each character in a head
+// after a wildcard matches either only path separators or only other
+// characters, or else the glob pattern is compiled into a program (see
+// needsProgram and usesProgram). Restarts never move backwards past the most
+// recent '**', and the '**' skips the path components it has already tried,
+// so the time matching takes grows linearly with the length of the path.
+func (p *Pattern) matchChunks(path string, starts []int) (bool, int) {
+	c := p.config
+
+	// record the offset where the head of the chunk was matched
+	record := func(chunk, offset int) {
+		if starts != nil {
+			starts[chunk] = offset
 		}
+	}
+
+	// done is the number of bytes matched by the chunks that can't be
+	// restarted any more, which is the count a failed match returns
+	var done int
 
-		patternMatched, count = matchDirectory(head, tail, path)
-		if patternMatched {
-			matchCount += count
-			path = path[count:]
-			pattern = tail
-			head, tail, kind = nextPattern(pattern)
+	// Note that there can be only one chunk that is simple, and it will be the
+	// first one. It has no wildcard before it to restart, so it either matches
+	// at the start of the path or not at all.
+	var offset, next int
+	if first := p.chunk(0); first.kind == patternSimple {
+		matched, count := c.matchSimple(first.head, path)
+		if !matched {
+			return false, count
 		}
+
+		record(0, 0)
+		offset = count
+		next++
 	}
 
-	for kind == patternRecursive && patternMatched && (len(head) > 0 || len(tail) > 0 || len(path) > 0) {
-		if len(head) == 0 {
-			pattern = tail
-			head, tail, kind = nextPattern(pattern)
-			if len(head) == 0 {
-				// pattern is just "**" wildcard, which matches everything
-				matched = true
-				matchCount += len(path)
-				return matched, matchCount
+	// The restart points are the chunks of the most recent '*' and '**', and
+	// the offsets where their heads were last tried.
+	star, starOffset := -1, 0
+	globstar, globstarOffset := -1, 0
+
+	entering := true
+	for {
+		if next < len(p.chunks) {
+			chunk := p.chunks[next]
+			if entering && (chunk.kind == patternRecursive || globstar < 0) {
+				// the chunks before this one are never restarted
+				done = offset
 			}
+
+			if entering && chunk.kind == patternRecursive {
+				globstar, globstarOffset = next, offset
+				star = -1
+			} else if entering {
+				star, starOffset = next, offset
+			}
+
+			switch {
+			case len(chunk.head) == 0 && chunk.kind == patternRecursive:
+				// a trailing "**" matches the rest of the path
+				record(next, len(path))
+				return true, len(path)
+			case len(chunk.head) == 0:
+				// A trailing '*' matches the rest of the path if there are no
+				// more path separators in it. If there are, the '*' stops at
+				// the first one and can't be restarted.
+				starOffset = offset + c.nameLength(path[offset:])
+				if globstar < 0 {
+					done = starOffset
+				}
+
+				if starOffset == len(path) {
+					record(next, len(path))
+					return true, len(path)
+				}
+			default:
+				matched, count := c.matchSimple(chunk.head, path[offset:])
+				if matched {
+					record(next, offset)
+					offset += count
+					next++
+					entering = true
+					continue
+				}
+			}
+		} else if offset == len(path) {
+			// every chunk matched, and so did the entire path
+			return true, offset
 		}
 
-		patternMatched, count = matchRecursively(pattern, head, tail, path)
-		if patternMatched {
-			matchCount += count
-			path = path[count:]
-			pattern = tail
-			head, tail, kind = nextPattern(pattern)
+		// Mismatch. Restart the most recent '*' if it can consume another
+		// character, or else the most recent '**'.
+		entering = false
+		if star >= 0 {
+			if value, size := c.decode(path[starOffset:]); size > 0 && !c.isSeparator(value) {
+				starOffset += size
+				next, offset = star, starOffset
+				continue
+			}
+
+			if globstar >= 0 {
+				// Restarting the "**" before the first path separator after
+				// where its head was last matched fails the same way.
+				globstarOffset += c.nameLength(path[globstarOffset:])
+			}
+		}
+
+		if globstar < 0 || globstarOffset == len(path) {
+			return false, done
 		}
-	}
 
-	matched = patternMatched && len(path) == 0
-	return matched, matchCount
+		_, size := c.decode(path[globstarOffset:])
+		globstarOffset += size
+		next, offset = globstar, globstarOffset
+		star = -1
+	}
 }
@@ -3,84 +3,169 @@
 package glob
 
 // classIsValid accepts a pattern that starts with a left bracket and returns
-// true and the length of the class if the class is valid, and false and the
-// number of characters found before an error was encountered otherwise.
-func classIsValid(pattern []rune) (int, error) {
+// the length of the class and nil if the class is valid, and the number of
+// characters found before an error was encountered and a *PatternError
+// otherwise. The offsets in the error are relative to the start of the class.
+func (c *config) classIsValid(pattern []rune) (int, error) {
+	var err error
+
+	count := c.scanClass(pattern, func(classErr *PatternError) bool {
+		err = classErr
+		return false
+	})
+
+	return count, err
+}
+
+// scanClass accepts a pattern that starts with a left bracket and returns the
+// length of the class. Each error found is passed to report, along with its
+// offset relative to the start of the class. Scanning continues past the error
+// if report returns true. Otherwise, scanning stops and the number of
+// characters found before the error is returned.
+func (c *config) scanClass(pattern []rune, report func(*PatternError) bool) int {
 	// A zero-length pattern is invalid
 	if len(pattern) == 0 {
-		return 0, ErrGlobZeroLength
+		report(newPatternError(pattern, 0, ErrGlobZeroLength))
+		return 0
 	}
 
 	// The pattern must start with '['
 	if pattern[0] != '[' {
-		return 1, ErrGlobNoLeftBracket
+		report(newPatternError(pattern, 0, ErrGlobNoLeftBracket))
+		return 1
 	}
 
-	// The pattern must be at least 3 characters long, or if the first character
-	// after the leading '[' is '!', it must be at least 4 characters long.
-	if len(pattern) < 3 || pattern[1] == '!' && len(pattern) < 4 {
+	// The pattern must be at least 3 characters long, or if the class is
+	// negated, it must be at least 4 characters long.
+	first := c.firstMember(pattern)
+	if len(pattern) < 3 || first == 2 && len(pattern) < 4 {
 		// return length up to, but not including the end to indicate that not
 		// only is the pattern invalid, but that it is too short. In effect, it
 		// allows nextValidPattern to return a more intuitive character count.
-		return len(pattern) - 1, ErrGlobTruncated
+		if report(newPatternError(pattern, len(pattern), ErrGlobTruncated).within(constructClass, 0)) {
+			// when scanning continues, the class consumes the rest of the pattern
+			return len(pattern)
+		}
+		return len(pattern) - 1
+	}
+
+	var stop bool
+	var truncated bool
+	fail := func(offset int, kind globError) {
+		truncated = truncated || kind == ErrGlobTruncated
+		stop = !report(newPatternError(pattern, offset, kind).within(constructClass, 0))
 	}
 
+	// lo is namedClass if the last member of the class was a class name or
+	// Unicode property, which can't start a range
+	const namedClass = -1
+
 	var i int = 1
-	var err error
 	var lo rune
-	for done := false; !done && err == nil && i < len(pattern); i++ {
+	var loIndex int
+	var done bool
+	for ; !done && !stop && i < len(pattern); i++ {
 		token := pattern[i]
 		switch token {
-		case escapeCharacter:
+		case c.escape:
+			// a Unicode property, such as "\p{Greek}", is a member of the class
+			if end := c.propertyEnd(pattern, i); end >= 0 {
+				if propertyTable(string(pattern[i+3:end])) == nil {
+					fail(i+3, ErrGlobUnknownProperty)
+				}
+				lo = namedClass
+				loIndex = i
+				i = end
+				continue
+			}
+
 			if i < len(pattern)-1 {
 				// skip the escape character
 				i++
 				lo = pattern[i]
-				switch lo {
-				case '!', '-', ']', escapeCharacter:
-					// These are the only valid escape sequences, but the class
-					// can't end in "\]"
-					if lo == ']' && i == len(pattern)-1 {
-						err = ErrGlobTruncated
-					}
-				default:
-					// invalid escape sequence
-					err = ErrGlobInvalidEscape
+				loIndex = i
+				if !c.isEscapable(lo, true) {
+					fail(i, ErrGlobInvalidEscape)
+				} else if lo == ']' && i == len(pattern)-1 {
+					// the class can't end in "\]"
+					fail(len(pattern), ErrGlobTruncated)
 				}
 			} else {
-				err = ErrGlobTruncated
+				fail(len(pattern), ErrGlobTruncated)
 			}
 		case ']':
-			// found end of class if the right bracket is not the first character, nor
-			// is it the second character in a negated class
-			if i != 1 || i != 2 && pattern[1] == '!' {
+			// found end of class if the right bracket is not the first member
+			// of the class, which is the second character in a negated class
+			if i != first {
 				done = true
 			}
 		case '-':
 			// if the hyphen is the first character in the class, or the second
 			// character in a negated class, or the last character just before
 			// the terminating right bracket, then it's just a literal hyphen.
-			if i == 1 || i == 2 && pattern[1] == '!' || i == len(pattern)-2 {
+			if i == first || i+1 < len(pattern) && pattern[i+1] == ']' {
+				continue
+			}
+
+			// the pattern ends before the range does
+			if i == len(pattern)-1 {
+				fail(len(pattern), ErrGlobTruncated)
 				continue
 			}
 
-			// We have a range. Verify it's valid (doesn't include '/')
+			// We have a range. Verify it's valid (doesn't include '/', and
+			// neither end is a class name or Unicode property)
 			i++
 			hi := pattern[i]
-			if GlobSeparator > lo && GlobSeparator < hi || hi == escapeCharacter {
-				err = ErrGlobInvalidRange
+			if end := c.classNameEnd(pattern, i); end >= 0 || lo == namedClass {
+				fail(loIndex, ErrGlobInvalidRange)
+				if end >= 0 {
+					i = end
+				}
+				continue
+			}
+			escaped := hi == c.escape && i < len(pattern)-1
+			if escaped && c.lenientEscapes {
+				hi = pattern[i+1]
+			}
+
+			if GlobSeparator > lo && GlobSeparator < hi || hi == c.escape && !c.lenientEscapes {
+				fail(loIndex, ErrGlobInvalidRange)
+			}
+
+			// an escaped end of range is invalid unless escapes are lenient,
+			// but skip the escaped character anyway so it can't end the class.
+			if escaped {
+				i++
+			}
+		case '[':
+			// a class name, such as "[:alpha:]", is a member of the class;
+			// otherwise, '[' is a literal character
+			end := namedClassEnd(pattern, i)
+			if end < 0 {
+				lo = token
+				loIndex = i
+				continue
+			}
+
+			if posixClasses[string(pattern[i+2:end-1])] == nil {
+				fail(i+2, ErrGlobUnknownClassName)
 			}
+			lo = namedClass
+			loIndex = i
+			i = end
 		default:
 			// capture the current token in case the next one starts a range
 			lo = token
+			loIndex = i
 		}
 	}
 
-	if err == nil && pattern[i-1] != ']' {
-		err = ErrGlobTruncated
+	if !stop && !truncated && !done {
+		fail(len(pattern), ErrGlobTruncated)
 	}
 
-	return i, err
+	return i
 }
 
 // matchClass accepts a class pattern and compares a character against
@@ -90,18 +175,21 @@ func classIsValid(pattern []rune) (int, error) {
 //
 // The string enclosed by the brackets cannot be empty; therefore ']' can be
 // allowed between the brackets, provided that it is the first character. Thus,
-// "[][!]" matches the three characters '[', ']', and '!'.).
-func matchClass(pattern []rune, value rune, negated bool) bool {
-	var escaped bool
+// "[][!]" matches the three characters '[', ']', and '!'.). Likewise, "[!]]"
+// matches any character except ']'. A class may also
+// name POSIX classes, such as "[[:alpha:]_]", which match the characters
+// posixClasses says they do, and Unicode properties, such as "[\p{Greek}0-9]"
+// or "[\P{L}]".
+func (c *config) matchClass(pattern []rune, value rune, negated bool) bool {
 	var matched bool
 
 	// match a separator only if it's explicit in the class pattern and it's not
 	// negated.
-	if value == Separator {
+	if c.isSeparator(value) {
 		if !negated {
 			// match the separator only if it is explicitly listed
 			for _, token := range pattern {
-				if token == GlobSeparator {
+				if token == GlobSeparator || token == c.globSeparator {
 					return true
 				}
 			}
@@ -115,27 +203,34 @@ func matchClass(pattern []rune, value rune, negated bool) bool {
 	// Deal with the empty and negated empty classes.
 	i := 1
 	if negated {
-		// move past the exclamation point
+		// move past the '!' or '^'
 		i++
 	}
+	first := i
 
 	// loop through characters in the pattern attempting to match one of
 	// them to the character. Stop when there is either a match, or the
-	// pattern is consumed.
+	// pattern is consumed. The last character in the pattern is the closing
+	// bracket, so never read past it, even if the class is malformed.
 	var lo, hi rune
+	last := len(pattern) - 1
 
-	for !matched && ((pattern[i] != ']' || i == 1) || escaped) {
+	for !matched && i < last && (pattern[i] != ']' || i == first) {
 		token := pattern[i]
-		// If token is '\' and escaped is not true, set it to true, otherwise
-		// ensure escaped is false.
-		escaped = !escaped && (token == escapeCharacter)
+
+		// match a class name, such as "[:alpha:]", or a Unicode property,
+		// such as "\p{Greek}", as a whole
+		if end := c.classNameEnd(pattern, i); end >= 0 && end < last {
+			matched = c.inClassName(pattern[i:end+1], value)
+			i = end + 1
+			continue
+		}
 
 		// skip past an escape character so literals such
 		// as ']', '-', and '\' can be matched.
-		if escaped {
+		if token == c.escape && i+1 < last {
 			i++
 			token = pattern[i]
-			escaped = false
 		}
 
 		// initialize lo and hi to the same character
@@ -147,25 +242,21 @@ func matchClass(pattern []rune, value rune, negated bool) bool {
 		// if the next character in the pattern is '-' and it is not the last
 		// character in the class, then we have a range. The value of "lo" is
 		// already set, so capture the end of the range in "hi".
-		if token == '-' && pattern[i+1] != ']' {
+		if token == '-' && i+1 < last && pattern[i+1] != ']' {
 			i++
 			token = pattern[i]
-			escaped = !escaped && (token == escapeCharacter)
 
 			// skip past an escape character so literals such
 			// as ']', '-', and '\' can be matched.
-			if escaped {
+			if token == c.escape && i+1 < last {
 				i++
 				token = pattern[i]
-				escaped = false
 			}
 			hi = token
 		}
 
 		// Match if the character is in range.
-		if value >= lo && value <= hi {
-			matched = true
-		}
+		matched = c.inRange(value, lo, hi)
 	}
 
 	// reverse the sense of matched if the class is negated.
@@ -174,28 +265,65 @@ func matchClass(pattern []rune, value rune, negated bool) bool {
 	return matched
 }
 
+// classNameEnd returns the offset of the last character of the class name,
+// such as "[:alpha:]", or Unicode property, such as "\p{Greek}", that starts at
+// offset i in a class, or -1 if there isn't one there.
+func (c *config) classNameEnd(class []rune, i int) int {
+	if end := namedClassEnd(class, i); end >= 0 {
+		return end
+	}
+
+	return c.propertyEnd(class, i)
+}
+
+// inClassName returns true if the character is in the class name or has the
+// Unicode property found by classNameEnd.
+func (c *config) inClassName(name []rune, value rune) bool {
+	if name[0] == '[' {
+		return c.inNamedClass(name[2:len(name)-2], value)
+	}
+
+	return c.inProperty(name, value)
+}
+
+// inRange returns true if the character is in the range from lo to hi,
+// inclusive. If the case of characters is folded, then it's also in the range
+// if any other case of the character is.
+func (c *config) inRange(value, lo, hi rune) bool {
+	if value >= lo && value <= hi {
+		return true
+	}
+
+	return c.foldCase && inFoldedRange(value, lo, hi)
+}
+
 // getClass accepts a pattern that starts with a left bracket and returns a
 // boolean indicating if the class is negated and a slice of runes containing
-// the class pattern.
-func getClass(pattern []rune) (negated bool, subpattern []rune) {
+// the class pattern. The class ends where the validator says it does, so the
+// two never disagree.
+func (c *config) getClass(pattern []rune) (negated bool, subpattern []rune) {
 	// Assume the pattern is valid and starts with '[', for why else would we be here?
-	var i int = 1
+	subpattern = pattern[:c.classLength(pattern)]
+	negated = c.firstMember(subpattern) == 2
+	return
+}
 
-	negated = pattern[1] == '!'
-	for done := false; !done; i++ {
-		token := pattern[i]
-		switch token {
-		case ']':
-			if i != 1 || i != 2 && negated {
-				done = true
-			}
-		case escapeCharacter:
-			// skip it
-			i++
-		}
+// firstMember returns the offset of the first member of the class at the start
+// of the pattern, which is 2 if the class is negated and 1 otherwise. A class
+// is negated if it starts with "[!" or, if caret negation is on, "[^".
+func (c *config) firstMember(pattern []rune) int {
+	if len(pattern) > 1 && (pattern[1] == '!' || c.caretNegation && pattern[1] == '^') {
+		return 2
 	}
 
-	subpattern = pattern[:i]
-	negated = pattern[1] == '!'
-	return
+	return 1
+}
+
+// classLength returns the length of the class at the start of the pattern,
+// ignoring any errors in it. If the class is never closed, it's the length of
+// the pattern.
+func (c *config) classLength(pattern []rune) int {
+	return c.scanClass(pattern, func(*PatternError) bool {
+		return true
+	})
 }
@@ -159,6 +159,15 @@ func TestGetClass(t *testing.T) {
 			expected: []rune(`[![]`),
 			negated:  true,
 		},
+		{
+			class:    []rune(`[!]]`),
+			expected: []rune(`[!]]`),
+			negated:  true,
+		},
+		{
+			class:    []rune(`[^]]`),
+			expected: []rune(`[^]`),
+		},
 		{
 			class:    []rune(`[![\]]`),
 			expected: []rune(`[![\]]`),
@@ -201,14 +210,14 @@ func TestGetClass(t *testing.T) {
 		// with leading zeros
 		name := fmt.Sprintf("%03d", i+1)
 		t.Run(name, func(t *testing.T) {
-			count, err := classIsValid((test.class))
+			count, err := defaultConfig.classIsValid((test.class))
 			t.Logf("%s classIsValid: %v, %d", name, err, count)
 			if !errors.Is(err, test.err) {
 				t.Errorf("Test ok %s (%s): Expected %s. Actual %s.", name, string(test.class), test.err, err)
 			}
 
 			if err == nil && test.err == nil {
-				negated, actual := getClass(test.class)
+				negated, actual := defaultConfig.getClass(test.class)
 
 				if test.negated != negated {
 					t.Errorf("Test negated %s (%s): Expected %t. Actual %t.", name, string(test.class), test.negated, negated)
@@ -270,6 +279,12 @@ func TestMatchClass(t *testing.T) {
 			values:   []rune{']'},
 			expected: []bool{false},
 		},
+		{
+			// ']' is the first member of the negated class
+			pattern:  []rune("[!]]"),
+			values:   []rune{']', '!', 'a'},
+			expected: []bool{false, true, true},
+		},
 		{
 			// match any character except  'a', 'b', 'c',
 			// and '/' on Linux or '\' on Windows
@@ -407,9 +422,9 @@ func TestMatchClass(t *testing.T) {
 				t.Errorf("Test %s(%s): Invalid test setup. The number of test value (%d), expected results (%d)", name, string(test.pattern), len(test.values), len(test.expected))
 			}
 
-			negated, pattern := getClass(test.pattern)
+			negated, pattern := defaultConfig.getClass(test.pattern)
 			for i, value := range test.values {
-				matched := matchClass(pattern, value, negated)
+				matched := defaultConfig.matchClass(pattern, value, negated)
 				if matched != test.expected[i] {
 					t.Errorf("Test %s[%02d] (\"%s\", %#U): Expected match %t. Actual match %t.", name, i+1, string(test.pattern), value, test.expected[i], matched)
 				}
@@ -418,6 +433,60 @@ func TestMatchClass(t *testing.T) {
 	}
 }
 
+// Verify "[^...]" is a negated class when caret negation is on, and "]" may be
+// its first member, just as it may in "[!...]".
+func TestCaretNegation(t *testing.T) {
+	testIO := []struct {
+		opts     Options
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{Options{CaretNegation: true}, "[^abc]", "d", true},
+		{Options{CaretNegation: true}, "[^abc]", "a", false},
+		{Options{CaretNegation: true}, "[^a-c]x", "dx", true},
+		{Options{CaretNegation: true}, "[^]]", "]", false},
+		{Options{CaretNegation: true}, "[^]]", "a", true},
+		{Options{CaretNegation: true}, "[^]a]", "a", false},
+		{Options{CaretNegation: true}, "[^-a]", "-", false},
+		{Options{CaretNegation: true}, "[^^]", "^", false},
+		{Options{CaretNegation: true}, "[a^]", "^", true},
+		{Options{CaretNegation: true}, `[\^a]`, "^", true},
+		{Options{CaretNegation: true}, "a[^b]c", "a/c", false},
+		{Options{CaretNegation: true}, "*.[^o]", "main.c", true},
+		{Options{CaretNegation: true, Braces: true}, "{[^x],y}", "z", true},
+		{Options{}, "[^abc]", "^", true},
+		{Options{}, "[^abc]", "d", false},
+		{Options{Dialect: UnixDialect}, "[^abc]", "d", true},
+		{Options{Dialect: WindowsDialect}, "[^abc]", "d", true},
+		{Options{Dialect: UnixDialect, Escape: '^'}, "[^]]", "]", true},
+		{Options{Dialect: UnixDialect, Escape: '^'}, "[^]]", "a", false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if _, err := test.opts.Validate(test.pattern); err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if matched, _ := test.opts.Match(test.pattern, test.path); matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+
+	if _, err := Validate(`[\^]`); !errors.Is(err, ErrGlobInvalidEscape) {
+		t.Errorf("Expected %v without caret negation. Actual %v.", ErrGlobInvalidEscape, err)
+	}
+
+	if _, err := (Options{CaretNegation: true}).Validate("[^]"); !errors.Is(err, ErrGlobTruncated) {
+		t.Errorf("Expected %v. Actual %v.", ErrGlobTruncated, err)
+	}
+}
+
 // Initialize expected values for tests that always match
 func testsMatchAll(values []rune) []bool {
 	tests := make([]bool, len(values))
@@ -4,15 +4,15 @@ package glob
 
 // matchSimple compares a simple path string to a simple glob pattern. If any
 // character sequence in path, starting with its first character, matches the
-// entire pattern, then return true and the number of runes matched. Otherwise,
-// return false and the number of runes matched before a mismatch occurred.
+// entire pattern, then return true and the number of bytes matched. Otherwise,
+// return false and the number of bytes matched before a mismatch occurred.
 //
 // Simple matching includes matching literal characters one-for-one, matching
 // any single character to the '?' wildcard, and matching any single character
 // to any character defined by a set (aka, character class).
 //
 // if a class match fails, exit the loop
-func matchSimple(pattern, path []rune) (bool, int) {
+func (c *config) matchSimple(pattern []rune, path string) (bool, int) {
 	var index int
 	var matchedCount int
 	var isEscaped bool
@@ -26,11 +26,11 @@ func matchSimple(pattern, path []rune) (bool, int) {
 mismatch:
 	for index = 0; index < len(pattern) && matchedCount < len(path) && matched; index++ {
 		token := pattern[index]
-		value := path[matchedCount]
+		value, size := c.decode(path[matchedCount:])
 		if isEscaped {
 			isEscaped = false
-			if token == value {
-				matchedCount++
+			if c.equal(token, value) {
+				matchedCount += size
 			} else {
 				// consume a pattern-character and break out of the loop
 				index++
@@ -39,16 +39,16 @@ mismatch:
 			}
 		} else {
 			switch token {
-			case escapeCharacter:
+			case c.escape:
 				// skip past the escape character so literals, such as ']', '-',
 				// '\', and '*' can be matched.
 				isEscaped = true
 			case '[':
-				negated, classPattern := getClass(pattern[index:])
+				negated, classPattern := c.getClass(pattern[index:])
 
-				matched = matchClass(classPattern, value, negated)
+				matched = c.matchClass(classPattern, value, negated)
 				if matched {
-					matchedCount++
+					matchedCount += size
 					// set index past the end of class character
 					index += len(classPattern) - 1
 				} else {
@@ -58,19 +58,21 @@ mismatch:
 				}
 			case '?':
 				// match any single character except a path separator
-				if value != Separator {
-					matchedCount++
+				if !c.isSeparator(value) {
+					matchedCount += size
 				} else {
 					// consume a pattern-character and break out of the loop
 					index++
 					matched = false
 					break mismatch
 				}
-			case GlobSeparator:
-				// an alternative path separator for Windows, so value can match
-				// either this or the regular path separator, '\'.
-				if value == Separator || value == token {
-					matchedCount++
+			case GlobSeparator, c.globSeparator:
+				// a path separator in a glob pattern is '/', or either '/' or
+				// '\' in the Windows dialect when '\' isn't the escape
+				// character, so value can match either this or the path
+				// separator in use, such as '\' for Windows paths.
+				if c.isSeparator(value) || value == token {
+					matchedCount += size
 				} else {
 					// consume a pattern-character and break out of the loop
 					index++
@@ -79,8 +81,8 @@ mismatch:
 				}
 			default:
 				// match a literal character in the pattern to one in the path.
-				if token == value {
-					matchedCount++
+				if c.equal(token, value) {
+					matchedCount += size
 				} else {
 					// consume a pattern-character and break out of the loop
 					index++
@@ -91,6 +93,11 @@ mismatch:
 		}
 	}
 
+	// a trailing escape character matches nothing, even at the end of the path
+	if matched && !isEscaped && index == len(pattern)-1 && pattern[index] == c.escape {
+		index++
+	}
+
 	// ensure the entire pattern was used
 	matched = matched && index == len(pattern)
 	return matched, matchedCount
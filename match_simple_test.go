@@ -14,7 +14,7 @@ func TestMatchSimple(t *testing.T) {
 		pattern []rune   // test pattern
 		paths   []string // one or more paths to test
 		matched []bool   // one expected success/failure match for each path
-		counts  []int    // the expected number of runes matched on success/failure
+		counts  []int    // the expected number of bytes matched on success/failure
 	}{
 		{
 			pattern: []rune{},
@@ -52,6 +52,18 @@ func TestMatchSimple(t *testing.T) {
 			matched: []bool{true, false},
 			counts:  []int{1, 0},
 		},
+		{
+			pattern: []rune{'x', escapeCharacter},
+			paths:   []string{"x", "xy", "y"},
+			matched: []bool{true, true, false},
+			counts:  []int{1, 1, 0},
+		},
+		{
+			pattern: []rune("?界"),
+			paths:   []string{"世界", "世x", "界"},
+			matched: []bool{true, false, false},
+			counts:  []int{6, 3, 3},
+		},
 	}
 
 	for i, test := range testIO {
@@ -64,7 +76,7 @@ func TestMatchSimple(t *testing.T) {
 			}
 
 			for i, path := range test.paths {
-				matched, count := matchSimple(test.pattern, []rune(path))
+				matched, count := defaultConfig.matchSimple(test.pattern, path)
 				if matched != test.matched[i] {
 					t.Errorf("Test %s [%d of %d] (%s, %s): Expected %t. Actual %t.", name, i+1, len(test.counts), string(test.pattern), path, test.matched[i], matched)
 				}
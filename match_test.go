@@ -4,7 +4,9 @@ package glob
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPatterns(t *testing.T) {
@@ -21,6 +23,13 @@ func TestPatterns(t *testing.T) {
 		{"*", "abc", true},
 		{"*", SeparatorString + "abc", false},
 		{"**" + GlobSeparatorString + "a" + GlobSeparatorString + "?*.txt", "a" + SeparatorString + "a" + SeparatorString + ".txt", false},
+		{"**a*b**c", "axb", false},
+		{"**a*b**c", "axb" + SeparatorString + "zc", true},
+		{"a\\*b", "a*b", true},
+		{"a\\*b", "axb", false},
+		{"\\[ab]", "[ab]", true},
+		{"[a-]]", "-]", true},
+		{"[a-]x", "ax", true},
 	}
 
 	for i, test := range testIO {
@@ -121,6 +130,12 @@ func TestMatchAll(t *testing.T) {
 			matched: []bool{false},
 			counts:  []int{0},
 		},
+		{
+			pattern: "a*/x",
+			paths:   []string{"ab" + SeparatorString + "y"},
+			matched: []bool{false},
+			counts:  []int{1},
+		},
 		{
 			// The path to match is "\Users\foo\ba世\界.txt" for Windows and "/Users/foo/ba世/界.txt" for Linux
 			pattern: "/Users/**/[bc]a[!a-qsu-z]/?*.txt",
@@ -163,3 +178,490 @@ func TestMatchAll(t *testing.T) {
 		})
 	}
 }
+
+// Verify a directory chunk matches the way it always has.
+func TestMatchDirectoryChunk(t *testing.T) {
+	testIO := []struct {
+		pattern []rune   // test pattern (implicit leading '*')
+		paths   []string // one or more paths to test
+		matched []bool   // one expected success/failure match for each path
+		counts  []int    // the expected number of runes matched on success/failure
+	}{
+		{
+			pattern: []rune{},
+			paths:   []string{"a", "ab", "abc", SeparatorString, SeparatorString + "a", "a" + SeparatorString},
+			matched: []bool{true, true, true, false, false, false},
+			counts:  []int{1, 2, 3, 0, 0, 1},
+		},
+		{
+			pattern: []rune("z"),
+			paths:   []string{"z", "z1", "z12", "12z", SeparatorString, "z" + SeparatorString, SeparatorString + "z"},
+			matched: []bool{true, false, false, true, false, false, false},
+			counts:  []int{1, 0, 0, 3, 0, 0, 0},
+		},
+		{
+			pattern: []rune{GlobSeparator},
+			paths:   []string{"x", "x" + SeparatorString, "xyz" + SeparatorString, SeparatorString, SeparatorString + "xyz", "abc" + SeparatorString + "xyz"},
+			matched: []bool{false, true, true, true, false, false},
+			counts:  []int{0, 2, 4, 1, 0, 0},
+		},
+		{
+			pattern: []rune("?"),
+			paths:   []string{"x", "x" + SeparatorString, "xyz" + SeparatorString, SeparatorString, "abc" + SeparatorString + "xyz"},
+			matched: []bool{true, false, false, false, false},
+			counts:  []int{1, 0, 0, 0, 0},
+		},
+		{
+			pattern: []rune("aa?"),
+			paths:   []string{"aaaaa"},
+			matched: []bool{true},
+			counts:  []int{5},
+		},
+		{
+			pattern: []rune("."),
+			paths:   []string{"a."},
+			matched: []bool{true},
+			counts:  []int{2},
+		},
+		{
+			// {"*", "abc", true},
+			pattern: []rune{},
+			paths:   []string{"abc"},
+			matched: []bool{true},
+			counts:  []int{3},
+		},
+		{
+			// {"*", SeparatorString + "abc", false},
+			pattern: []rune{},
+			paths:   []string{SeparatorString + "abc"},
+			matched: []bool{false},
+			counts:  []int{0},
+		},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if len(test.paths) != len(test.counts) || len(test.paths) != len(test.matched) {
+				t.Errorf("Test %s: Invalid setup. The number of test paths (%d), expected counts (%d), and expected matches (%d) must be the same", name, len(test.paths), len(test.counts), len(test.matched))
+			}
+
+			for i, path := range test.paths {
+				matched, count := MustCompile("*" + string(test.pattern)).Match(path)
+				if matched != test.matched[i] {
+					t.Errorf("Test %s [%d of %d] (%s, %s): Expected %t. Actual %t.", name, i+1, len(test.counts), string(test.pattern), path, test.matched[i], matched)
+					break
+				}
+				if count != test.counts[i] {
+					t.Errorf("Test %s [%d of %d] (%s, %s): Expected %d. Actual %d.", name, i+1, len(test.counts), string(test.pattern), path, test.counts[i], count)
+				}
+			}
+		})
+	}
+}
+
+// Verify a recursive chunk, and the directory chunks that follow it, match
+// the way they always have.
+func TestMatchRecursiveChunk(t *testing.T) {
+	testIO := []struct {
+		pattern string   // test pattern
+		paths   []string // one or more paths to test
+		matched []bool   // one expected success/failure match for each path
+		counts  []int    // the expected number of runes matched on success/failure
+	}{
+		{
+			pattern: "**",
+			paths:   []string{"a", "ab", "abc", SeparatorString, SeparatorString + "a", "a" + SeparatorString},
+			matched: []bool{true, true, true, true, true, true},
+			counts:  []int{1, 2, 3, 1, 2, 2},
+		},
+		{
+			pattern: "**c",
+			paths:   []string{"a" + SeparatorString + "b" + SeparatorString + "c"},
+			matched: []bool{true},
+			counts:  []int{5},
+		},
+		{
+			pattern: "**c*t",
+			paths:   []string{"a" + SeparatorString + "b" + SeparatorString + "cat", "a" + SeparatorString + "b" + SeparatorString + "cut", "a" + SeparatorString + "b" + SeparatorString + "caught", "a" + SeparatorString + "b" + SeparatorString + "c" + SeparatorString + "ut", "abcdefghijklmnopqrst"},
+			matched: []bool{true, true, true, false, true},
+			counts:  []int{7, 7, 10, 0, 20},
+		},
+		{
+			pattern: "**a*b*c",
+			paths:   []string{"abcd" + SeparatorString + "xyz" + SeparatorString + "abc"},
+			matched: []bool{true},
+			counts:  []int{12},
+		},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if len(test.paths) != len(test.counts) || len(test.paths) != len(test.matched) {
+				t.Errorf("Test %s: Invalid setup. The number of test paths (%d), expected counts (%d), and expected matches (%d) must be the same.", name, len(test.paths), len(test.counts), len(test.matched))
+			}
+
+			if len(test.pattern) < 2 {
+				t.Errorf("Test %s: pattern \"%s\" is too short. It must start with '**'.", name, test.pattern)
+			}
+
+			if string(test.pattern[:2]) != "**" {
+				t.Errorf("Test %s: pattern \"%s\" must start with '**'.", name, test.pattern)
+			}
+
+			for i, path := range test.paths {
+				matched, count := MustCompile(test.pattern).Match(path)
+				if matched != test.matched[i] {
+					t.Errorf("Test %s[%02d]: Expected %t. Actual %t. Test %d of %d (%s, %s).", name, i+1, test.matched[i], matched, i+1, len(test.counts), test.pattern, test.paths[i])
+					break
+				}
+				if count != test.counts[i] {
+					t.Errorf("Test %s[%02d]: Expected %d. Actual %d. Test %d of %d (%s, %s).", name, i+1, test.counts[i], count, i+1, len(test.counts), test.pattern, test.paths[i])
+				}
+			}
+		})
+	}
+}
+
+// Verify the most recent '*' or '**' is restarted until the rest of the glob
+// pattern matches, and that the chunks agree with a program compiled from the
+// same glob pattern.
+func TestMatchRestarts(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"**c", "cc", true},
+		{"**a", "aba", true},
+		{"**?", "ba", true},
+		{"a**b", "abab", true},
+		{"a**b", "aba", false},
+		{"*c", "cc", true},
+		{"*a*b", "aab", true},
+		{"*a*b", "aa" + SeparatorString + "b", false},
+		{"**a*b", "ab" + SeparatorString + "ab", true},
+		{"**a*b", "ab" + SeparatorString + "ac", false},
+		{"**/a*/**/b", SeparatorString + "ab" + SeparatorString + "c" + SeparatorString + "b", true},
+		{"**/a*/**/b", SeparatorString + "b" + SeparatorString + "ac" + SeparatorString + "x" + SeparatorString + "b", true},
+		{"**/a*/**/b", SeparatorString + "b" + SeparatorString + "ca" + SeparatorString + "b", false},
+		{"**/x/*", "x" + SeparatorString + "x" + SeparatorString + "y", true},
+		{"**/x/*", SeparatorString + "x" + SeparatorString + "y" + SeparatorString + "z", false},
+		{"**x*/*y", "ax" + SeparatorString + "bx" + SeparatorString + "cy", true},
+		{"**x*/*y", "ax" + SeparatorString + "b" + SeparatorString + "cy", false},
+		{"**ab*", "aab" + SeparatorString + "ab", true},
+		{"**ab*", "aab" + SeparatorString + "ac", false},
+		{"*[!x]*?b", "xabb", true},
+		{"*[!x]*?b", "xab", false},
+		{"src/**/*_test.go", "src" + SeparatorString + "a" + SeparatorString + "b_test" + SeparatorString + "c_test.go", true},
+		{"src/**/*_test.go", "src" + SeparatorString + "a_test.go" + SeparatorString + "c.go", false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p := MustCompile(test.pattern)
+			matched, count := p.Match(test.path)
+			if matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+
+			// the program counts the runes a failed match got through differently,
+			// so the counts only have to agree when the path matched
+			prog := &Pattern{source: p.source, config: p.config, prog: p.config.compileProgram([]rune(test.pattern))}
+			if progMatched, progCount := prog.Match(test.path); progMatched != matched || (matched && progCount != count) {
+				t.Errorf("Test %s (%s, %s): Expected the program to return (%t, %d). Actual (%t, %d).", name, test.pattern, test.path, matched, count, progMatched, progCount)
+			}
+		})
+	}
+}
+
+// Verify a head after a wildcard that may match both a path separator and
+// another character is matched by a program, or by one only when the path has a
+// '/' that isn't a path separator.
+func TestMatchAmbiguousHeads(t *testing.T) {
+	testIO := []struct {
+		opts     Options
+		pattern  string
+		path     string
+		program  bool
+		expected bool
+	}{
+		{Options{}, "*[/a]*", "a/", true, true},
+		{Options{}, "**[/a]*", "ba/", true, true},
+		{Options{}, "[/a]*", "/b", false, true},
+		{Options{}, "*[!a]*", "b/", false, false},
+		{Options{Separator: '\\'}, "*/*b", `x/a\b`, true, true},
+		{Options{Separator: '\\'}, "*/*b", `x\ab`, false, true},
+		{Options{Separator: '\\'}, "src/*.go", "src/a.go", false, true},
+		{Options{Separator: 'k', FoldCase: true}, "*K*", "aKb", true, true},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p, err := test.opts.Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if program := p.usesProgram(test.path); program != test.program {
+				t.Errorf("Test %s (%s, %s): Expected to use a program %t. Actual %t.", name, test.pattern, test.path, test.program, program)
+			}
+
+			if matched, _ := p.Match(test.path); matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+}
+
+// adversarialPatterns are glob patterns that make a matcher that backtracks to
+// every earlier wildcard take exponential time, and one that restarts every
+// '**' from each character of the path take quadratic time, along with a path
+// that they don't match, built from n copies of a component.
+var adversarialPatterns = []struct {
+	pattern   string
+	component string
+}{
+	{"a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*a*b", "a"},
+	{"**/a*/**/a*/**/b", "/a"},
+	{"**a*b", "a"},
+	{"**a*a*a*a*b", "aaaaaaaa/"},
+	{"**a*/*b", "aaaaaaaa/"},
+	{"**/*/*/*/*/b", "/aaaa"},
+}
+
+// Verify patterns that make a backtracking matcher take exponential or
+// quadratic time are matched in linear time.
+func TestMatchIsLinear(t *testing.T) {
+	for i, test := range adversarialPatterns {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p := MustCompile(test.pattern)
+			path := strings.ReplaceAll(strings.Repeat(test.component, 100000), GlobSeparatorString, SeparatorString)
+
+			begin := time.Now()
+			if matched, _ := p.Match(path); matched {
+				t.Errorf("Test %s (%s): Expected no match", name, test.pattern)
+			}
+
+			if elapsed := time.Since(begin); elapsed > 5*time.Second {
+				t.Errorf("Test %s (%s): Took %v", name, test.pattern, elapsed)
+			}
+		})
+	}
+}
+
+// BenchmarkMatchAdversarial matches each adversarial pattern against paths of
+// growing length. The time per path character should stay about the same.
+func BenchmarkMatchAdversarial(b *testing.B) {
+	for i, test := range adversarialPatterns {
+		p := MustCompile(test.pattern)
+		for _, n := range []int{100, 1000, 10000} {
+			path := strings.ReplaceAll(strings.Repeat(test.component, n), GlobSeparatorString, SeparatorString)
+			b.Run(fmt.Sprintf("%03d/%d", i+1, len(path)), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					p.Match(path)
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(path)), "ns/char")
+			})
+		}
+	}
+}
+
+// Verify a compiled pattern matches paths without allocating memory, whether
+// it's broken down into chunks or compiled into a program.
+func TestPatternMatchAllocs(t *testing.T) {
+	testIO := []struct {
+		opts    Options
+		pattern string
+		path    string
+	}{
+		{Options{}, "a/b?c", "a" + SeparatorString + "bxc"},
+		{Options{}, "src/[a-z]*.go", "src" + SeparatorString + "main.go"},
+		{Options{}, "*/x.go", "cmd" + SeparatorString + "x.go"},
+		{Options{}, "*/*_test.go", "cmd" + SeparatorString + "main.go"},
+		{Options{}, "**/testdata/*.json", "a" + SeparatorString + "b" + SeparatorString + "testdata" + SeparatorString + "x.json"},
+		{Options{}, "**/界/*.txt", "世" + SeparatorString + "界" + SeparatorString + "x.txt"},
+		{Options{FoldCase: true}, "**/README*", "docs" + SeparatorString + "readme.md"},
+		{Options{ExactBytes: true}, "**/*.txt", "\xff" + SeparatorString + "\xfe.txt"},
+		{Options{Braces: true}, "*.{go,mod}", "go.mod"},
+		{Options{Braces: true}, "src/{cmd,internal/**}/*.go", "src" + SeparatorString + "internal" + SeparatorString + "a" + SeparatorString + "x.go"},
+		{Options{Braces: true}, "x{01..12}", "x07"},
+		{Options{NoDot: true}, "**/*.go", "a" + SeparatorString + ".b" + SeparatorString + "x.go"},
+		{Options{ExtGlob: true}, "!(*_test).go", "main.go"},
+		{Options{ExtGlob: true, ExactBytes: true}, "+(\xff|a).txt", "a\xffa.txt"},
+		{Options{Dialect: WindowsDialect}, "C:/Users/*", `\\?\C:\Users\x`},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p, err := test.opts.Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if raceEnabled && p.usesProgram(test.path) {
+				t.Skip("The race detector drops the machines the program reuses")
+			}
+
+			allocs := testing.AllocsPerRun(100, func() {
+				p.Match(test.path)
+			})
+			if allocs != 0 {
+				t.Errorf("Test %s (%s, %s): Expected no allocations. Actual %v.", name, test.pattern, test.path, allocs)
+			}
+		})
+	}
+}
+
+// Verify the only allocation matching a long UNC path is the one that
+// replaces its prefix.
+func TestPatternMatchAllocsUNC(t *testing.T) {
+	p, err := Options{Dialect: WindowsDialect, Braces: true}.Compile("//server/share/*.{go,mod}")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if raceEnabled {
+		t.Skip("The race detector drops the machines the program reuses")
+	}
+
+	path := `\\?\UNC\server\share\go.mod`
+	if matched, _ := p.Match(path); !matched {
+		t.Fatalf("Expected %s to match.", path)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { p.Match(path) }); allocs != 1 {
+		t.Errorf("Expected 1 allocation. Actual %v.", allocs)
+	}
+}
+
+func BenchmarkPatternMatch(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		pattern string
+		path    string
+	}{
+		{"simple", "src/glob/match?.go", "src" + SeparatorString + "glob" + SeparatorString + "match.go"},
+		{"directory", "src/*/*_test.go", "src" + SeparatorString + "glob" + SeparatorString + "match_test.go"},
+		{"recursive", "**/testdata/*.json", "a" + SeparatorString + "b" + SeparatorString + "c" + SeparatorString + "testdata" + SeparatorString + "x.json"},
+	}
+
+	for _, bm := range benchmarks {
+		p := MustCompile(bm.pattern)
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				p.Match(bm.path)
+			}
+
+			if allocs := testing.AllocsPerRun(100, func() { p.Match(bm.path) }); allocs != 0 {
+				b.Errorf("Expected no allocations. Actual %v.", allocs)
+			}
+		})
+	}
+}
+
+// Verify MatchE never panics, and that it agrees with Validate and Match.
+func FuzzMatchE(f *testing.F) {
+	seeds := []struct {
+		pattern string
+		path    string
+	}{
+		{"", ""},
+		{"*", "abc"},
+		{"**a*b**c", "axb" + SeparatorString + "zc"},
+		{"/Users/**/[bc]a[!a-qsu-z]/?*.txt", SeparatorString + "Users" + SeparatorString + "foo" + SeparatorString + "ba世" + SeparatorString + "界.txt"},
+		{"[a-", "a"},
+		{"[!]]", "]"},
+		{"[\\]", "]"},
+		{"a\\", "a"},
+		{"[.0-\\\\]", "\\"},
+		{"*.{go,m[o-p]d,{a,b}*}", "x.bz"},
+		{"{-05..012}", "-04"},
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed.pattern, seed.path)
+	}
+
+	f.Fuzz(func(t *testing.T, pattern, path string) {
+		matched, count, err := MatchE(pattern, path)
+
+		_, validateErr := Validate(pattern)
+		if (err == nil) != (validateErr == nil) {
+			t.Fatalf("MatchE(%q, %q) returned error %v, but Validate returned %v", pattern, path, err, validateErr)
+		}
+
+		errs := ValidateAll(pattern)
+		if (err == nil) != (len(errs) == 0) {
+			t.Fatalf("MatchE(%q, %q) returned error %v, but ValidateAll returned %v", pattern, path, err, errs)
+		}
+
+		if err != nil {
+			return
+		}
+
+		if expectedMatch, expectedCount := Match(pattern, path); matched != expectedMatch || count != expectedCount {
+			t.Fatalf("MatchE(%q, %q) returned (%t, %d), but Match returned (%t, %d)", pattern, path, matched, count, expectedMatch, expectedCount)
+		}
+
+		captures, captured := MatchCaptures(pattern, path)
+		if captured != matched {
+			t.Fatalf("MatchCaptures(%q, %q) returned %t, but Match returned %t", pattern, path, captured, matched)
+		}
+
+		for _, c := range captures {
+			if c.Start > c.End || c.ByteStart > c.ByteEnd || c.ByteEnd > len(path) {
+				t.Fatalf("MatchCaptures(%q, %q) returned an invalid capture %+v", pattern, path, c)
+			}
+		}
+	})
+}
+
+// Verify the chunks of a glob pattern match the same paths as a program
+// compiled from it, and capture exactly the same parts of the path.
+func FuzzMatchChunks(f *testing.F) {
+	f.Add("**/a*/**/b", "/a/b/ab/b")
+	f.Add("*[!x]*?b", "xabb")
+	f.Add("a**b*c", "abc/bcc")
+	f.Add("**[/a]*", "ba/")
+
+	f.Fuzz(func(t *testing.T, pattern, path string) {
+		opts := Options{}
+		p, err := opts.Compile(pattern)
+		if err != nil || p.usesProgram(path) {
+			return
+		}
+
+		prog := &Pattern{source: pattern, config: p.config, prog: p.config.compileProgram([]rune(pattern))}
+
+		// the counts of a failed match differ, so only compare them on success
+		matched, count := p.Match(path)
+		if progMatched, progCount := prog.Match(path); matched != progMatched || (matched && count != progCount) {
+			t.Fatalf("Match(%q, %q) returned (%t, %d), but the program returned (%t, %d)", pattern, path, matched, count, progMatched, progCount)
+		}
+
+		captures, _ := p.MatchCaptures(path)
+		progCaptures, _ := prog.MatchCaptures(path)
+		if fmt.Sprint(captures) != fmt.Sprint(progCaptures) {
+			t.Fatalf("MatchCaptures(%q, %q) returned %+v, but the program returned %+v", pattern, path, captures, progCaptures)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"unicode/utf8"
+)
+
+// CouldMatchUnder reports whether the compiled glob pattern could match a path
+// inside the directory, so a walker can skip a directory without reading it
+// if it returns false. For example, "docs/**/*.md" could match paths under
+// "docs" and "docs/a/b", but not under "vendor". A path is inside the
+// directory if it starts with the directory, a path separator and a name, and
+// "." or an empty string is the directory that relative paths are relative
+// to. It never returns false if something inside the directory matches, but
+// it may return true if nothing does, such as when a class in the glob
+// pattern can't match any character.
+func (p *Pattern) CouldMatchUnder(dir string) bool {
+	path := dir
+	if dir == "." {
+		path = ""
+	} else if r, _ := utf8.DecodeLastRuneInString(dir); dir != "" && !p.config.isSeparator(r) {
+		path = dir + string(p.config.separator)
+	}
+
+	if p.config.windows {
+		path, _ = trimLongPathPrefix(path)
+	}
+
+	if p.usesProgram(path) {
+		return p.prog.partial(p.config, path)
+	}
+
+	return p.partialChunks(path)
+}
+
+// partialChunks is like matchChunks, but it returns true if the chunks could
+// match the path followed by more characters, that is, if the path runs out
+// before the chunks do. The chunks before a '**' only need to match the start
+// of the path, since the '**' can consume the rest of it, so it only restarts
+// the most recent '*'.
+func (p *Pattern) partialChunks(path string) bool {
+	c := p.config
+
+	var offset, next int
+	if first := p.chunk(0); first.kind == patternSimple {
+		matched, count := c.matchSimple(first.head, path)
+		if !matched {
+			// the head may go on past the end of the path
+			return count == len(path)
+		}
+
+		offset = count
+		next++
+	}
+
+	star, starOffset := -1, 0
+
+	entering := true
+	for {
+		if next < len(p.chunks) {
+			chunk := p.chunks[next]
+			switch {
+			case offset == len(path), chunk.kind == patternRecursive:
+				// the path ran out before the chunks did, or a '**' can
+				// consume the rest of it
+				return true
+			case entering:
+				star, starOffset = next, offset
+			}
+
+			if len(chunk.head) == 0 {
+				// a trailing '*' could match the rest of the path if there
+				// are no more path separators in it
+				starOffset = offset + c.nameLength(path[offset:])
+				if starOffset == len(path) {
+					return true
+				}
+			} else {
+				matched, count := c.matchSimple(chunk.head, path[offset:])
+				if matched {
+					offset += count
+					next++
+					entering = true
+					continue
+				}
+
+				if offset+count == len(path) {
+					// the head may go on past the end of the path
+					return true
+				}
+			}
+		}
+
+		// Mismatch, or the chunks match the path itself and nothing longer.
+		// Restart the most recent '*' if it can consume another character.
+		entering = false
+		if star < 0 {
+			return false
+		}
+
+		value, size := c.decode(path[starOffset:])
+		if size == 0 || c.isSeparator(value) {
+			return false
+		}
+
+		starOffset += size
+		next, offset = star, starOffset
+	}
+}
@@ -0,0 +1,156 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCouldMatchUnder(t *testing.T) {
+	testIO := []struct {
+		opts     Options
+		pattern  string
+		dir      string
+		expected bool
+	}{
+		{Options{}, "docs/**/*.md", "docs", true},
+		{Options{}, "docs/**/*.md", "docs/a/b", true},
+		{Options{}, "docs/**/*.md", "vendor", false},
+		{Options{}, "docs/**/*.md", "doc", false},
+		{Options{}, "docs/**/*.md", "docsx", false},
+		{Options{}, "docs/**/*.md", ".", true},
+		{Options{}, "docs/**/*.md", "", true},
+		{Options{}, "docs/**/*.md", "docs/", true},
+		{Options{}, "src/*.go", "src", true},
+		{Options{}, "src/*.go", "src/a", false},
+		{Options{}, "src/*/*.go", "src/a", true},
+		{Options{}, "src/*/*.go", "src/a/b", false},
+		{Options{}, "*.go", "a", false},
+		{Options{}, "*.go", ".", true},
+		{Options{}, "*", "a", false},
+		{Options{}, "*/", "a", false},
+		{Options{}, "*/*", "a", true},
+		{Options{}, "a/b", "a", true},
+		{Options{}, "a/b", "a/b", false},
+		{Options{}, "a/b/", "a/b", false},
+		{Options{}, "a/b/c", "a/b", true},
+		{Options{}, "", ".", false},
+		{Options{}, "**", "a/b/c", true},
+		{Options{}, "a**", "a/b/c", true},
+		{Options{}, "a**", "b", false},
+		{Options{}, "**/x", "a", true},
+		{Options{}, "a*/**/b", "ab/c", true},
+		{Options{}, "a*/**/b", "b/c", false},
+		{Options{}, "*a/*b/c", "xa/yb", true},
+		{Options{}, "*a/*b/c", "xa/yc", false},
+		{Options{}, "*a*/c", "bab", true},
+		{Options{}, "*a*/c", "bbb", false},
+		{Options{}, "[a-c]?/d", "bx", true},
+		{Options{}, "[a-c]?/d", "dx", false},
+		{Options{}, `\*/d`, "*", true},
+		{Options{}, `\*/d`, "a", false},
+		{Options{}, "/usr/*/bin", "/", true},
+		{Options{}, "/usr/*/bin", "/usr", true},
+		{Options{}, "/usr/*/bin", "/usr/local", true},
+		{Options{}, "/usr/*/bin", "/opt", false},
+		{Options{}, "/usr/*/bin", "usr", false},
+
+		// brace expressions, extended globs and the leading-period rules are
+		// matched by a program
+		{Options{Braces: true}, "{src,docs}/*.go", "src", true},
+		{Options{Braces: true}, "{src,docs}/*.go", "vendor", false},
+		{Options{Braces: true}, "{a,b/c}/d", "b", true},
+		{Options{Braces: true}, "{a,b/c}/d", "b/c", true},
+		{Options{Braces: true}, "{a,b/c}/d", "a/c", false},
+		{Options{Braces: true}, "{a}/*.go", "{a}", true},
+		{Options{Braces: true}, "{a}/*.go", "a", false},
+		{Options{ExtGlob: true}, "!(vendor)/**/*.go", "src", true},
+		{Options{ExtGlob: true}, "!(vendor)/**/*.go", "vendor", false},
+		{Options{ExtGlob: true}, "!(vendor)/**/*.go", "vendor/a", false},
+		{Options{NoDot: true}, "*/*.go", ".git", false},
+		{Options{NoDot: true}, "*/*.go", "src", true},
+		{Options{NoHiddenDirs: true}, "**/*.go", ".git", false},
+		{Options{NoHiddenDirs: true}, "**/*.go", "a/b", true},
+
+		// the options select the path separator and case folding
+		{Options{Separator: '\\'}, "docs/*/*.md", `docs\a`, true},
+		{Options{Separator: '\\'}, "docs/*/*.md", `docs\a\b`, false},
+		{Options{FoldCase: true}, "docs/**", "DOCS", true},
+		{Options{Dialect: WindowsDialect}, "C:/Users/*/*.txt", `C:\users\me`, true},
+		{Options{Dialect: WindowsDialect}, "C:/Users/*/*.txt", `C:\Windows`, false},
+		{Options{Dialect: WindowsDialect}, "C:/Users/**", `\\?\C:\Users`, true},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			p, err := test.opts.Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if actual := p.CouldMatchUnder(test.dir); actual != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.dir, test.expected, actual)
+			}
+
+			prog := &Pattern{source: p.source, config: p.config, prog: p.config.compileProgram(p.config.runes(test.pattern))}
+			if actual := prog.CouldMatchUnder(test.dir); actual != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t from the program. Actual %t.", name, test.pattern, test.dir, test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCouldMatchUnderAllocs(t *testing.T) {
+	p := MustCompile("docs/**/*.md")
+	allocs := testing.AllocsPerRun(100, func() {
+		p.CouldMatchUnder("docs/a/")
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations. Actual %v.", allocs)
+	}
+}
+
+// FuzzCouldMatchUnder checks that the chunks and the program agree on whether
+// a glob pattern could match a path inside a directory, and that it could if it
+// matches a path inside the directory.
+func FuzzCouldMatchUnder(f *testing.F) {
+	f.Add("docs/**/*.md", "docs/a/b.md", 2)
+	f.Add("*a*/c", "bab/c", 1)
+	f.Add("a*/**/b", "ab/c/b", 3)
+	f.Add("*/x/*", "a/x/b", 1)
+
+	opts := Options{Dialect: UnixDialect}
+	f.Fuzz(func(t *testing.T, pattern, path string, depth int) {
+		p, err := opts.Compile(pattern)
+		if err != nil {
+			return
+		}
+
+		prog := &Pattern{source: pattern, config: p.config, prog: p.config.compileProgram(p.config.runes(pattern))}
+
+		// try the directories the path is in, and the path itself
+		names := strings.Split(path, "/")
+		if depth < 0 || depth >= len(names) {
+			depth = len(names) - 1
+		}
+		dir := strings.Join(names[:depth+1], "/")
+
+		possible := p.CouldMatchUnder(dir)
+		if progPossible := prog.CouldMatchUnder(dir); possible != progPossible {
+			t.Fatalf("CouldMatchUnder(%q, %q) returned %t, but the program returned %t", pattern, dir, possible, progPossible)
+		}
+
+		// a path separator at the end of the path after the directory only
+		// says that the path is the directory itself
+		inside := depth < len(names)-1 && path != dir+"/"
+		if matched, _ := p.Match(path); matched && inside && !possible {
+			t.Fatalf("Match(%q, %q) returned true, but CouldMatchUnder(%q) returned false", pattern, path, dir)
+		}
+	})
+}
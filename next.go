@@ -7,7 +7,7 @@ package glob
 // just the next sub-pattern and its type. It assumes the glob pattern is valid.
 // If the sub-pattern is directory or recursive, it removes the leading
 // asterisk(s).
-func nextPattern(pattern []rune) (head, tail []rune, kind patternType) {
+func (c *config) nextPattern(pattern []rune) (head, tail []rune, kind patternType) {
 	var start int
 	var end int
 	kind = patternSimple
@@ -29,8 +29,6 @@ func nextPattern(pattern []rune) (head, tail []rune, kind patternType) {
 		}
 	}
 
-	var inClass bool // true if parsing inside a character class
-
 	// find the end of the sub-pattern. An asterisk starts another chunk unless
 	// it is found within a character class.
 endPattern:
@@ -38,23 +36,21 @@ endPattern:
 		token := pattern[end]
 
 		switch token {
-		case escapeCharacter:
-			// token is an escape character only if it's in a class, otherwise
-			// it's a path separator for Windows and just another character for
-			// Linux.
-			if inClass {
-				// skip the escaped character so we don't miss a real ']'
+		case c.escape:
+			// skip the escaped character so an escaped '*' doesn't start
+			// another chunk, an escaped '[' doesn't start a class, and we
+			// don't miss a real ']'. A trailing escape character has nothing
+			// to skip.
+			if end+1 < len(pattern) {
 				end++
 			}
 		case '[':
-			inClass = true
-		case ']':
-			inClass = false
+			// skip the class, which may contain a '*' or, in a class name
+			// such as "[:alpha:]", a ']' that doesn't end it
+			end += c.classLength(pattern[end:]) - 1
 		case '*':
-			if !inClass {
-				// end of current sub-pattern; '*' starts another one
-				break endPattern
-			}
+			// end of current sub-pattern; '*' starts another one
+			break endPattern
 		}
 	}
 
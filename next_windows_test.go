@@ -31,7 +31,7 @@ func TestNextPatternWindows(t *testing.T) {
 
 	for _, test := range testIO {
 		t.Run(test.name, func(t *testing.T) {
-			head, tail, kind := nextPattern(test.pattern)
+			head, tail, kind := defaultConfig.nextPattern(test.pattern)
 
 			if len(head) != len(test.expectedHead) {
 				t.Errorf("Test %s: Expected \"%s\". Actual \"%s\". Pattern lengths do not match.", test.name, string(test.expectedHead), string(head))
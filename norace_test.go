@@ -0,0 +1,8 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+//go:build !race
+
+package glob
+
+// raceEnabled is true if the tests run with the race detector.
+const raceEnabled = false
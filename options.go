@@ -0,0 +1,295 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+// Options select how glob patterns are matched at run time, so a program can,
+// for example, match glob patterns against Windows paths while running on
+// Linux. The zero value selects the defaults for the operating system the
+// program was built for, which are the ones the package-level functions use.
+//
+//	opts := glob.Options{Separator: '\\'}
+//	matched, _ := opts.Match("src/*.go", `src\main.go`)
+//
+// Glob patterns always use '/' as a path separator, and it matches either '/'
+// or the path separator selected by the options.
+//
+// The Dialect selects the rest of the file system's conventions, so a Linux
+// program can also validate and match glob patterns the way Windows would:
+//
+//	opts := glob.Options{Dialect: glob.WindowsDialect}
+//	matched, _ := opts.Match("c:/users/*/*.TXT", `C:\Users\me\notes.txt`)
+type Options struct {
+	// Separator is the path separator used by the paths being matched. If it's
+	// zero, the path separator of the dialect is used.
+	Separator rune
+
+	// Dialect selects the file system conventions used to validate glob
+	// patterns and match paths. The zero value is NativeDialect.
+	Dialect Dialect
+
+	// Escape is the escape character used in glob patterns. If it's zero, the
+	// escape character is '\\'. Another escape character, such as '`' or '^',
+	// frees '\\' to be a path separator in glob patterns using the Windows
+	// dialect. It must not be a character that has a meaning of its own in a
+	// glob pattern: '/', '*', '?', '[', ']', '!' or '-', '{', '}' or ',' if
+	// brace expressions are on, or '(', ')', '|', '+' or '@' if extended globs
+	// are on. Validate, ValidateAll and Compile return
+	// ErrGlobInvalidEscapeRune if it is.
+	Escape rune
+
+	// LenientEscapes allows any character to be escaped, as bash does.
+	// Otherwise, only the escape character and these may be escaped:
+	//
+	//   - '?', '[' and '*' outside of a class;
+	//   - '{', '}' and ',' outside of a class, if brace expressions are on;
+	//   - '(', ')', '|', '+', '@' and '!' outside of a class, if extended
+	//     globs are on;
+	//   - '!', '-' and ']' inside a class, and '^' too with caret negation.
+	LenientEscapes bool
+
+	// FoldCase makes matching case-insensitive. Literal characters, including
+	// escaped ones, match any character that is the same under simple Unicode
+	// case folding, and a character is in a class if any case of it is, so
+	// "[A-Z]" matches 'k' and the Kelvin sign '\u212A'. The Windows dialect always
+	// folds case.
+	FoldCase bool
+
+	// CaretNegation makes "[^abc]" a negated class, like "[!abc]", as it is in
+	// bash, gitignore and Go's path.Match. Otherwise, '^' is a literal
+	// character. '^' may then be escaped in a class. The Unix and Windows
+	// dialects always allow caret negation, unless '^' is the escape
+	// character.
+	CaretNegation bool
+
+	// NoDot makes a '.' at the start of a path component special, as glob(7)
+	// does: it's only matched by a literal '.' in the glob pattern, never by
+	// '?', '*', a class or an extended glob, so "*" doesn't match ".env" but
+	// ".*" does. '**' can't match a hidden file or directory at the end of
+	// its match either, but it still crosses hidden directories, so "**/*.go"
+	// matches ".git/x.go", unless NoHiddenDirs is also set.
+	NoDot bool
+
+	// NoHiddenDirs stops '**' from crossing a hidden directory, one whose name
+	// starts with a '.', so "**/*.go" doesn't match ".git/x.go". A literal
+	// ".git/" in the glob pattern still does.
+	NoHiddenDirs bool
+
+	// ExactBytes matches glob patterns and paths byte for byte, such as the
+	// names os.ReadDir returns from a Linux file system, which may be any
+	// bytes. Each byte that isn't part of a valid UTF-8 sequence is a
+	// character of its own, which only the same byte matches, and which '?'
+	// or a class matches as one character. Otherwise, every invalid byte is
+	// replaced with U+FFFD, so different names may match the same glob
+	// pattern. Valid UTF-8 sequences are single characters either way.
+	ExactBytes bool
+
+	// Braces turns on brace expressions, such as "*.{go,mod}" and
+	// "{1..10}". As in bash, braces without a comma or a range between them,
+	// such as "{a}", are still literal characters. Otherwise, '{', '}' and ','
+	// are literal characters, as they were before brace expressions were
+	// supported, so existing glob patterns such as "a}" keep their meaning.
+	Braces bool
+
+	// ExtGlob turns on the extended globs of bash's extglob option, which
+	// match a list of patterns separated by '|':
+	//
+	//	?(list)  zero or one of the patterns
+	//	*(list)  zero or more of the patterns
+	//	+(list)  one or more of the patterns
+	//	@(list)  exactly one of the patterns
+	//	!(list)  anything except one of the patterns
+	//
+	// Like '*', they never match a path separator unless a pattern in the
+	// list does, and "!(list)" never does. A '(' that doesn't follow one of
+	// the operators, and a ')' or '|' outside of an extended glob, are
+	// literal characters. '(', ')', '|', '+', '@' and '!' may be escaped.
+	ExtGlob bool
+}
+
+// config is a set of Options with all of the defaults filled in. The matching
+// functions are its methods.
+type config struct {
+	separator       rune
+	reservedSymbols reservedSymbolSet
+
+	// windows is true for the Windows dialect, where both '/' and '\' are
+	// path separators, drive designators are allowed, device names are
+	// reserved, and long-path prefixes are ignored.
+	windows bool
+
+	// foldCase is true if characters match without regard to case
+	foldCase bool
+
+	// caretNegation is true if "[^abc]" is a negated class, like "[!abc]"
+	caretNegation bool
+
+	// noDot is true if a '.' at the start of a path component is only
+	// matched by a literal '.', and noHiddenDirs is true if '**' doesn't
+	// cross a directory whose name starts with a '.'
+	noDot        bool
+	noHiddenDirs bool
+
+	// exactBytes is true if invalid UTF-8 bytes are matched exactly
+	exactBytes bool
+
+	// braces is true if brace expressions are recognized
+	braces bool
+
+	// extGlob is true if extended globs, such as "@(a|b)", are recognized
+	extGlob bool
+
+	escape         rune
+	lenientEscapes bool
+
+	// globSeparator is a path separator in glob patterns besides '/'. It's
+	// '\\' in the Windows dialect when '\\' isn't the escape character, and
+	// '/' otherwise.
+	globSeparator rune
+}
+
+// defaultConfig is used by the package-level functions.
+var defaultConfig = Options{}.config()
+
+// config returns the options with all of the defaults filled in.
+func (o Options) config() *config {
+	c := &config{separator: o.Separator, escape: o.Escape, lenientEscapes: o.LenientEscapes, foldCase: o.FoldCase, caretNegation: o.CaretNegation, noDot: o.NoDot, noHiddenDirs: o.NoHiddenDirs, exactBytes: o.ExactBytes, braces: o.Braces, extGlob: o.ExtGlob}
+	if c.escape == 0 {
+		c.escape = escapeCharacter
+	}
+
+	c.globSeparator = GlobSeparator
+	if o.Dialect == WindowsDialect && c.escape != '\\' {
+		c.globSeparator = '\\'
+	}
+
+	switch o.Dialect {
+	case UnixDialect:
+		c.reservedSymbols = unixReservedSymbols
+		c.caretNegation = true
+		if c.separator == 0 {
+			c.separator = '/'
+		}
+	case WindowsDialect:
+		c.reservedSymbols = windowsReservedSymbols
+		c.windows = true
+		c.foldCase = true
+		c.caretNegation = true
+		if c.separator == 0 {
+			c.separator = '\\'
+		}
+	default:
+		c.reservedSymbols = reservedSymbols
+		if c.separator == 0 {
+			c.separator = Separator
+		}
+	}
+
+	// "[^" starts a class with an escaped character if '^' is the escape
+	// character
+	if c.escape == '^' {
+		c.caretNegation = false
+	}
+
+	return c
+}
+
+// Compile is like the package-level Compile function, but the Pattern it
+// returns matches paths using these options.
+func (o Options) Compile(pattern string) (*Pattern, error) {
+	if _, err := o.Validate(pattern); err != nil {
+		return nil, err
+	}
+
+	return newPattern(pattern, o.config()), nil
+}
+
+// Match is like the package-level Match function, but it uses these options.
+func (o Options) Match(patternString, pathString string) (bool, int) {
+	return newPattern(patternString, o.config()).Match(pathString)
+}
+
+// MatchE is like the package-level MatchE function, but it uses these options.
+func (o Options) MatchE(patternString, pathString string) (bool, int, error) {
+	p, err := o.Compile(patternString)
+	if err != nil {
+		return false, 0, err
+	}
+
+	matched, count := p.Match(pathString)
+	return matched, count, nil
+}
+
+// Validate is like the package-level Validate function, but it uses the
+// reserved symbols and names of the dialect. The path separator doesn't change
+// which glob patterns are valid. If the escape character can't be one (see
+// Escape), it returns 0 and ErrGlobInvalidEscapeRune, whatever the pattern.
+func (o Options) Validate(pattern string) (int, error) {
+	c := o.config()
+	if err := c.checkEscape(); err != nil {
+		return 0, err
+	}
+
+	return c.validate(pattern)
+}
+
+// ValidateAll is like the package-level ValidateAll function, but it uses the
+// reserved symbols and names of the dialect. The path separator doesn't change
+// which glob patterns are valid. If the escape character can't be one (see
+// Escape), ErrGlobInvalidEscapeRune is the only error it returns.
+func (o Options) ValidateAll(pattern string) []error {
+	c := o.config()
+	if err := c.checkEscape(); err != nil {
+		return []error{err}
+	}
+
+	return c.validateAll(pattern)
+}
+
+// checkEscape returns ErrGlobInvalidEscapeRune if the escape character has a
+// meaning of its own in glob patterns.
+func (c *config) checkEscape() error {
+	switch c.escape {
+	case GlobSeparator, '*', '?', '[', ']', '!', '-':
+		return ErrGlobInvalidEscapeRune
+	case '{', '}', ',':
+		if c.braces {
+			return ErrGlobInvalidEscapeRune
+		}
+	case '(', ')', '|', '+', '@':
+		if c.extGlob {
+			return ErrGlobInvalidEscapeRune
+		}
+	}
+
+	return nil
+}
+
+// isSeparator returns true if the character is a path separator in a path.
+func (c *config) isSeparator(r rune) bool {
+	return r == c.separator || c.windows && (r == '/' || r == '\\')
+}
+
+// nameLength returns the number of bytes in a path before its first path
+// separator, or the length of the path if it doesn't have one.
+func (c *config) nameLength(path string) int {
+	var i int
+	for i < len(path) {
+		r, size := c.decode(path[i:])
+		if c.isSeparator(r) {
+			break
+		}
+		i += size
+	}
+
+	return i
+}
+
+// equal returns true if a literal character in a glob pattern matches a
+// character in a path.
+func (c *config) equal(token, value rune) bool {
+	if c.foldCase {
+		return equalFold(token, value)
+	}
+
+	return token == value
+}
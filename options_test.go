@@ -0,0 +1,355 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Verify the path separator can be selected at run time.
+func TestOptionsSeparator(t *testing.T) {
+	testIO := []struct {
+		separator rune
+		pattern   string
+		path      string
+		expected  bool
+	}{
+		{'\\', "src/*.go", `src\main.go`, true},
+		{'\\', "src/*.go", `src/main.go`, true},
+		{'\\', "*", `a\b`, false},
+		{'\\', "a?b", `a\b`, false},
+		{'\\', "a[!x]b", `a\b`, false},
+		{'\\', "a[/]b", `a\b`, true},
+		{'\\', "**/*.go", `a\b\c.go`, true},
+		{'\\', "**c*t", `a\b\c\ut`, false},
+		{'\\', "/Users/**/[bc]a[!a-qsu-z]/?*.txt", `\Users\foo\ba世\界.txt`, true},
+		{'/', "*", `a\b`, true},
+		{'/', "a?b", `a\b`, true},
+		{'/', "*", "a/b", false},
+		{'/', "**/*.go", "a/b/c.go", true},
+		{'/', "src/*.go", `src\main.go`, false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			opts := Options{Separator: test.separator}
+			matched, _ := opts.Match(test.pattern, test.path)
+			if matched != test.expected {
+				t.Errorf("Test %s (%q, %s, %s): Expected %t. Actual %t.", name, test.separator, test.pattern, test.path, test.expected, matched)
+			}
+
+			p, err := opts.Compile(test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s: pattern %s is invalid: %s", name, test.pattern, err)
+			}
+
+			if matched, _ := p.Match(test.path); matched != test.expected {
+				t.Errorf("Test %s (%q, %s, %s): Expected compiled pattern to return %t. Actual %t.", name, test.separator, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+}
+
+// Verify the zero value of Options matches the package-level functions.
+func TestOptionsDefaults(t *testing.T) {
+	var opts Options
+
+	pattern := "**" + GlobSeparatorString + "a" + GlobSeparatorString + "?*.txt"
+	path := "b" + SeparatorString + "a" + SeparatorString + "x.txt"
+	expectedMatch, expectedCount := Match(pattern, path)
+	matched, count := opts.Match(pattern, path)
+	if matched != expectedMatch || count != expectedCount {
+		t.Errorf("Expected (%t, %d). Actual (%t, %d).", expectedMatch, expectedCount, matched, count)
+	}
+
+	if _, _, err := opts.MatchE("[", path); err == nil {
+		t.Errorf("Expected MatchE to reject an invalid pattern")
+	}
+}
+
+// Verify the escape character can be changed and escapes can be lenient.
+func TestOptionsEscape(t *testing.T) {
+	testIO := []struct {
+		opts     Options
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{Options{Escape: '`'}, "a`*b", "a*b", true},
+		{Options{Escape: '`'}, "a`*b", "axb", false},
+		{Options{Escape: '`'}, "`[a]", "[a]", true},
+		{Options{Escape: '`'}, "[`]]", "]", true},
+		{Options{Escape: '`'}, "[!`!]", "!", false},
+		{Options{Escape: '`'}, "``*", "`x", true},
+		{Options{Escape: '^'}, "a^?", "a?", true},
+		{Options{Escape: '^'}, "a^?", "ab", false},
+		{Options{Escape: '`', Dialect: UnixDialect}, `a\b`, `a\b`, true},
+		{Options{Escape: '`', Dialect: UnixDialect}, `a\b`, "a/b", false},
+		{Options{Escape: '`', Dialect: WindowsDialect}, `src\*.go`, `src\main.go`, true},
+		{Options{Escape: '`', Dialect: WindowsDialect}, `src\*.go`, "src/main.go", true},
+		{Options{Escape: '`', Dialect: WindowsDialect}, "src\\`*.go", `src\*.go`, true},
+		{Options{Escape: '`', Dialect: WindowsDialect}, "src\\`*.go", `src\a.go`, false},
+		{Options{Escape: '`', Dialect: WindowsDialect}, `C:\**\[\]`, `C:\a\b\c\\`, true},
+		{Options{Escape: '`', Dialect: WindowsDialect}, `a[\]b`, `a\b`, true},
+		{Options{Dialect: WindowsDialect}, `a\*`, "a*", true},
+		{Options{LenientEscapes: true}, `\a\b\c`, "abc", true},
+		{Options{LenientEscapes: true}, `[\a-\c]`, "b", true},
+		{Options{LenientEscapes: true}, `[A-\]]`, "B", true},
+		{Options{LenientEscapes: true}, `[A-\]]`, "]", true},
+		{Options{LenientEscapes: true}, `\/`, "/", true},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			matched, _, err := test.opts.MatchE(test.pattern, test.path)
+			if err != nil {
+				t.Fatalf("Test %s: pattern %s is invalid: %s", name, test.pattern, err)
+			}
+
+			if matched != test.expected {
+				t.Errorf("Test %s (%+v, %s, %s): Expected %t. Actual %t.", name, test.opts, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+}
+
+// Verify which escape sequences are valid.
+func TestOptionsEscapeValidate(t *testing.T) {
+	testIO := []struct {
+		opts    Options
+		pattern string
+		offset  int // the offset of the error, if any
+		kind    error
+	}{
+		{Options{}, `a\*[\!]`, 0, nil},
+		{Options{Escape: '`'}, "a`*[`!]", 0, nil},
+		{Options{Escape: '`'}, "a`b", 2, ErrGlobInvalidEscape},
+		{Options{Escape: '`'}, "[`a]", 2, ErrGlobInvalidEscape},
+		{Options{}, `\a`, 1, ErrGlobInvalidEscape},
+		{Options{}, `[\a]`, 2, ErrGlobInvalidEscape},
+		{Options{}, `[a-\z]`, 1, ErrGlobInvalidRange},
+		{Options{LenientEscapes: true}, `\a`, 0, nil},
+		{Options{LenientEscapes: true}, `[\a]`, 0, nil},
+		{Options{LenientEscapes: true}, `[a-\z]`, 0, nil},
+		{Options{LenientEscapes: true}, `[+-\0]`, 1, ErrGlobInvalidRange},
+		{Options{Escape: '`', LenientEscapes: true}, "`a[`a-`z]", 0, nil},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			_, err := test.opts.Validate(test.pattern)
+			if !errors.Is(err, test.kind) || (err == nil) != (test.kind == nil) {
+				t.Fatalf("Test %s (%+v, %s): Expected %v. Actual %v.", name, test.opts, test.pattern, test.kind, err)
+			}
+
+			var patternErr *PatternError
+			if errors.As(err, &patternErr) && patternErr.Offset != test.offset {
+				t.Errorf("Test %s (%+v, %s): Expected offset %d. Actual %d.", name, test.opts, test.pattern, test.offset, patternErr.Offset)
+			}
+		})
+	}
+}
+
+// Verify an escape character that has a meaning of its own in glob patterns
+// is rejected.
+func TestOptionsInvalidEscapeRune(t *testing.T) {
+	testIO := []struct {
+		opts     Options
+		expected error
+	}{
+		{Options{Escape: '`'}, nil},
+		{Options{Escape: '^'}, nil},
+		{Options{Escape: '/'}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: '*'}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: '?'}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: '['}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: ']'}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: '!'}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: '-'}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: '{'}, nil},
+		{Options{Escape: '{', Braces: true}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: ','}, nil},
+		{Options{Escape: ',', Braces: true}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: '@'}, nil},
+		{Options{Escape: '@', ExtGlob: true}, ErrGlobInvalidEscapeRune},
+		{Options{Escape: '|', ExtGlob: true}, ErrGlobInvalidEscapeRune},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if _, err := test.opts.Validate("a*"); err != test.expected {
+				t.Errorf("Test %s (%q): Validate expected %v. Actual %v.", name, test.opts.Escape, test.expected, err)
+			}
+
+			if errs := test.opts.ValidateAll("a*"); len(errs) > 0 != (test.expected != nil) || len(errs) > 0 && errs[0] != test.expected {
+				t.Errorf("Test %s (%q): ValidateAll expected %v. Actual %v.", name, test.opts.Escape, test.expected, errs)
+			}
+
+			if _, err := test.opts.Compile("a*"); err != test.expected {
+				t.Errorf("Test %s (%q): Compile expected %v. Actual %v.", name, test.opts.Escape, test.expected, err)
+			}
+
+			if _, _, err := test.opts.MatchE("a*", "ab"); err != test.expected {
+				t.Errorf("Test %s (%q): MatchE expected %v. Actual %v.", name, test.opts.Escape, test.expected, err)
+			}
+		})
+	}
+}
+
+// Verify matching can ignore case.
+func TestOptionsFoldCase(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		folded   bool // expected with FoldCase
+		unfolded bool // expected without FoldCase
+	}{
+		{"readme.md", "README.MD", true, false},
+		{"[A-Z]*.go", "main.go", true, false},
+		{"[a-z]*.GO", "Main.go", true, false},
+		{"[!A-Z]*", "main", false, true},
+		{"[!a-z]*", "Main", false, true},
+		{"[K]", "k", true, false},
+		{"[a-z]", "\u212A", true, false},
+		{`\*A`, "*a", true, false},
+		{`\*A`, "xa", false, false},
+		{"*.TXT", "notes.txt", true, false},
+		{"**/TESTDATA/*.json", "src/a/testdata/X.JSON", true, false},
+		{"straße", "STRASSE", false, false},
+		{"straße", "STRAẞE", true, false},
+		{"ΣΊΣΥΦΟΣ", "σίσυφος", true, false},
+		{"*/X", "a/y", false, false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if matched, _ := (Options{FoldCase: true}).Match(test.pattern, test.path); matched != test.folded {
+				t.Errorf("Test %s (%s, %s): Expected %t with FoldCase. Actual %t.", name, test.pattern, test.path, test.folded, matched)
+			}
+
+			if matched, _ := (Options{}).Match(test.pattern, test.path); matched != test.unfolded {
+				t.Errorf("Test %s (%s, %s): Expected %t without FoldCase. Actual %t.", name, test.pattern, test.path, test.unfolded, matched)
+			}
+		})
+	}
+}
+
+// Verify NoDot and NoHiddenDirs make a leading '.' in a path component special.
+func TestOptionsNoDot(t *testing.T) {
+	testIO := []struct {
+		pattern      string
+		path         string
+		noDot        bool // expected with NoDot
+		noHiddenDirs bool // expected with NoHiddenDirs
+		both         bool // expected with both
+	}{
+		{"*", ".env", false, true, false},
+		{".*", ".env", true, true, true},
+		{"?env", ".env", false, true, false},
+		{"[.]env", ".env", false, true, false},
+		{"[!a]env", ".env", false, true, false},
+		{"a*", "a.b", true, true, true},
+		{"*.go", "x.go", true, true, true},
+		{"*/x", ".git/x", false, true, false},
+		{".git/*", ".git/x", true, true, true},
+		{"src/*", "src/.env", false, true, false},
+		{"src/*/x", "src/.git/x", false, true, false},
+		{"**", ".env", false, true, false},
+		{"**", "a/b", true, true, true},
+		{"src/**", "src/.env", false, true, false},
+		{"**x", ".x", false, true, false},
+		{"a**", "a.b/c", true, true, true},
+		{"**/*.go", "a/x.go", true, true, true},
+		{"**/*.go", ".git/x.go", true, false, false},
+		{"**/*.go", "a/.b/c/x.go", true, false, false},
+		{"**/*.go", "a/.x.go", false, true, false},
+		{"src/**", "src/.git/x", true, false, false},
+		{"**/x", ".a/.b/x", true, false, false},
+		{"**x", ".git/ax", true, false, false},
+		{"src/**", "src/.git", false, true, false},
+		{"**/.git/x", "a/.git/x", true, true, true},
+		{".git/**", ".git/a/b", true, true, true},
+		{"**/.git/**", "x/.git/y", true, true, true},
+		{"{.,x}env", ".env", true, true, true},
+		{"{*,x}", ".env", false, true, false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if matched, _ := (Options{Dialect: UnixDialect, Braces: true}).Match(test.pattern, test.path); !matched {
+				t.Errorf("Test %s (%s, %s): Expected a match by default.", name, test.pattern, test.path)
+			}
+
+			if matched, _ := (Options{Dialect: UnixDialect, NoDot: true, Braces: true}).Match(test.pattern, test.path); matched != test.noDot {
+				t.Errorf("Test %s (%s, %s): Expected %t with NoDot. Actual %t.", name, test.pattern, test.path, test.noDot, matched)
+			}
+
+			if matched, _ := (Options{Dialect: UnixDialect, NoHiddenDirs: true, Braces: true}).Match(test.pattern, test.path); matched != test.noHiddenDirs {
+				t.Errorf("Test %s (%s, %s): Expected %t with NoHiddenDirs. Actual %t.", name, test.pattern, test.path, test.noHiddenDirs, matched)
+			}
+
+			if matched, _ := (Options{Dialect: UnixDialect, NoDot: true, NoHiddenDirs: true, Braces: true}).Match(test.pattern, test.path); matched != test.both {
+				t.Errorf("Test %s (%s, %s): Expected %t with both. Actual %t.", name, test.pattern, test.path, test.both, matched)
+			}
+		})
+	}
+}
+
+// Verify NoDot applies to extended globs, and the captures of '**' include
+// the hidden directories it crosses.
+func TestOptionsNoDotExtGlobAndCaptures(t *testing.T) {
+	opts := Options{Dialect: UnixDialect, NoDot: true, ExtGlob: true}
+
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"@(.git|x)", ".git", true},
+		{"!(x)", ".env", false},
+		{"a!(x)", "a.env", true},
+		{"*(?)", ".a", false},
+		{"+(.a)", ".a.a", true},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if matched, _ := opts.Match(test.pattern, test.path); matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+
+	p, err := opts.Compile("src/**/*.go")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	captures, matched := p.MatchCaptures("src/a/.b/c.go")
+	if !matched || len(captures) != 2 || captures[0].Wildcard != "**" || captures[0].Start != 4 || captures[0].End != 8 {
+		t.Errorf("Expected ** to capture a/.b. Actual %+v.", captures)
+	}
+}
@@ -0,0 +1,77 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"unicode"
+)
+
+// posixClasses are the character classes that may be named in a class, such
+// as "[[:digit:]]" or "[![:space:][:punct:]]". Letters, spaces, punctuation and
+// the other classes follow Unicode, while digits and hexadecimal digits are
+// only the ASCII ones, as they are in the C library.
+var posixClasses = map[string]func(rune) bool{
+	"alnum":  func(r rune) bool { return unicode.IsLetter(r) || isDigit(r) },
+	"alpha":  unicode.IsLetter,
+	"blank":  func(r rune) bool { return r == '\t' || unicode.Is(unicode.Zs, r) },
+	"cntrl":  unicode.IsControl,
+	"digit":  isDigit,
+	"graph":  func(r rune) bool { return unicode.IsPrint(r) && r != ' ' },
+	"lower":  unicode.IsLower,
+	"print":  unicode.IsPrint,
+	"punct":  func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) },
+	"space":  unicode.IsSpace,
+	"upper":  unicode.IsUpper,
+	"xdigit": func(r rune) bool { return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F' },
+}
+
+// namedClassEnd returns the offset of the ']' that ends the class name, such
+// as "[:alpha:]", that starts at offset i in a class, or -1 if there isn't one
+// there. A class name is a '[' and a ':', followed by letters and ":]". If
+// anything else follows the "[:", the '[' is a literal character.
+func namedClassEnd(class []rune, i int) int {
+	if i+1 >= len(class) || class[i] != '[' || class[i+1] != ':' {
+		return -1
+	}
+
+	for j := i + 2; j+1 < len(class); j++ {
+		switch {
+		case class[j] == ':' && class[j+1] == ']':
+			return j + 1
+		case !isASCIILetter(class[j]):
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// isASCIILetter returns true if the rune is an ASCII letter.
+func isASCIILetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// inNamedClass returns true if the character is in the named class. A path
+// separator is never in a named class. If the case of characters is folded,
+// then a character is also in the class if any other case of it is, so
+// "[[:upper:]]" matches lowercase letters too.
+func (c *config) inNamedClass(name []rune, value rune) bool {
+	isMember := posixClasses[string(name)]
+	if isMember == nil || c.isSeparator(value) {
+		return false
+	}
+
+	if isMember(value) {
+		return true
+	}
+
+	if c.foldCase {
+		for r := unicode.SimpleFold(value); r != value; r = unicode.SimpleFold(r) {
+			if isMember(r) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
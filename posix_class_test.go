@@ -0,0 +1,191 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Verify classes may name POSIX character classes, mixed with other members.
+func TestPosixClasses(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"[[:digit:]]", "7", true},
+		{"[[:digit:]]", "a", false},
+		{"[[:digit:]]", "٣", false}, // Arabic-Indic digit three
+		{"[[:alpha:]]", "é", true},
+		{"[[:alpha:]]", "1", false},
+		{"[[:alnum:]]", "Z", true},
+		{"[[:alnum:]]", "_", false},
+		{"[[:upper:]]", "A", true},
+		{"[[:upper:]]", "a", false},
+		{"[[:lower:]]", "a", true},
+		{"[[:space:]]", " ", true},
+		{"[[:space:]]", "\n", true},
+		{"[[:blank:]]", "\t", true},
+		{"[[:blank:]]", "\n", false},
+		{"[[:cntrl:]]", "\x7f", true},
+		{"[[:punct:]]", "$", true},
+		{"[[:punct:]]", "!", true},
+		{"[[:punct:]]", "a", false},
+		{"[[:graph:]]", "~", true},
+		{"[[:graph:]]", " ", false},
+		{"[[:print:]]", " ", true},
+		{"[[:xdigit:]]", "f", true},
+		{"[[:xdigit:]]", "g", false},
+
+		// class names are mixed with ranges, literals and other names
+		{"[[:digit:]a-c_]", "b", true},
+		{"[[:digit:]a-c_]", "_", true},
+		{"[[:digit:]a-c_]", "d", false},
+		{"[x[:upper:][:digit:]]", "5", true},
+		{"[x[:upper:][:digit:]]", "x", true},
+		{"[x[:upper:][:digit:]]", "y", false},
+
+		// negated classes
+		{"[![:digit:]]", "a", true},
+		{"[![:digit:]]", "5", false},
+		{"[![:space:][:punct:]]", "a", true},
+		{"[![:space:][:punct:]]", "-", false},
+
+		// a path separator is never in a named class
+		{"a[[:punct:]]b", "a/b", false},
+		{"a[![:alpha:]]b", "a/b", false},
+		{"a[/[:punct:]]b", "a/b", true},
+
+		// the ']' of a class name doesn't end the class
+		{"[[:digit:]*]", "*", true},
+		{"[[:digit:]]*.txt", "1abc.txt", true},
+		{"*[[:digit:]]", "abc1", true},
+		{"**/v[[:digit:]]/*", "a/b/v2/c", true},
+		{"{[[:digit:]],x}y", "3y", true},
+
+		// a '[' that doesn't start a class name is a literal
+		{"[[:a]", "[", true},
+		{"[[:a]", ":", true},
+		{"[[a]", "[", true},
+		{"[a[]", "[", true},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if _, err := braces.Validate(test.pattern); err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if matched, _ := braces.Match(test.pattern, test.path); matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+}
+
+// Verify unknown class names, and ranges that start or end with a class name,
+// are reported.
+func TestPosixClassesValidate(t *testing.T) {
+	testIO := []struct {
+		pattern string
+		offset  int
+		kind    error
+	}{
+		{"[[:alpha:]]", 0, nil},
+		{"[[:alpah:]]", 3, ErrGlobUnknownClassName},
+		{"[[:Alpha:]]", 3, ErrGlobUnknownClassName},
+		{"[[::]]", 3, ErrGlobUnknownClassName},
+		{"a[b[:word:]]", 5, ErrGlobUnknownClassName},
+		{"[![:foo:]]", 4, ErrGlobUnknownClassName},
+		{"[[:digit:]-z]", 1, ErrGlobInvalidRange},
+		{"[a-[:digit:]]", 1, ErrGlobInvalidRange},
+		{"[[:digit:]-]", 0, nil},
+		{"[[:digit:]", 10, ErrGlobTruncated},
+		{"[[:digit:]]]", 0, nil},
+		{"[[:a]", 0, nil},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			_, err := Validate(test.pattern)
+			if !errors.Is(err, test.kind) || (err == nil) != (test.kind == nil) {
+				t.Fatalf("Test %s (%s): Expected %v. Actual %v.", name, test.pattern, test.kind, err)
+			}
+
+			var patternErr *PatternError
+			if errors.As(err, &patternErr) && patternErr.Offset != test.offset {
+				t.Errorf("Test %s (%s): Expected offset %d. Actual %d.", name, test.pattern, test.offset, patternErr.Offset)
+			}
+
+			errs := ValidateAll(test.pattern)
+			if (len(errs) == 0) != (test.kind == nil) || len(errs) > 0 && !errors.Is(errs[0], test.kind) {
+				t.Errorf("Test %s (%s): Expected ValidateAll to report %v first. Actual %v.", name, test.pattern, test.kind, errs)
+			}
+		})
+	}
+}
+
+// Verify case folding applies to class names, so "[[:upper:]]" matches lowercase
+// letters too.
+func TestPosixClassesFoldCase(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		folded   bool
+		unfolded bool
+	}{
+		{"[[:upper:]]", "a", true, false},
+		{"[[:lower:]]", "A", true, false},
+		{"[[:upper:]]", "1", false, false},
+		{"[![:upper:]]", "a", false, true},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if matched, _ := (Options{FoldCase: true}).Match(test.pattern, test.path); matched != test.folded {
+				t.Errorf("Test %s (%s, %s): Expected %t when folded. Actual %t.", name, test.pattern, test.path, test.folded, matched)
+			}
+
+			if matched, _ := Match(test.pattern, test.path); matched != test.unfolded {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.unfolded, matched)
+			}
+		})
+	}
+}
+
+func TestNamedClassEnd(t *testing.T) {
+	testIO := []struct {
+		class    string
+		offset   int
+		expected int
+	}{
+		{"[[:alpha:]]", 1, 9},
+		{"[[:alpha:]]", 0, -1},
+		{"[[:al-pha:]]", 1, -1},
+		{"[[:alpha]", 1, -1},
+		{"[[::]]", 1, 4},
+		{"[[", 1, -1},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if actual := namedClassEnd([]rune(test.class), test.offset); actual != test.expected {
+				t.Errorf("Test %s (%s, %d): Expected %d. Actual %d.", name, test.class, test.offset, test.expected, actual)
+			}
+		})
+	}
+}
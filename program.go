@@ -0,0 +1,740 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// A glob pattern with brace expressions can't be broken down into simple,
+// directory and recursive chunks, because a '*' may be inside one of the
+// alternatives. Instead, it's compiled into a program for a nondeterministic
+// finite automaton, and the automaton is simulated one path character at a
+// time, following every alternative at once (see Russ Cox, "Regular
+// Expression Matching: the Virtual Machine Approach"). The alternatives are
+// never expanded, so matching takes O(n×m) time, where n is the length of the
+// path and m is the length of the program, which is proportional to the length
+// of the glob pattern.
+//
+// Extended globs are compiled into the same program, except for a negated
+// pattern list, "!(list)", which matches the strings that the list doesn't.
+// Its list is compiled into a sub-program, and a thread inside the negation
+// carries the set of sub-program threads that are still alive after the
+// characters it has consumed, which is a state of the deterministic automaton
+// for the list. The sets are built lazily and shared by every thread in the
+// same state, so a negation adds at most one thread per state to each list of
+// threads, and matching time stays linear in the length of the path.
+
+// instOp is the operation performed by an instruction in a program.
+type instOp int
+
+const (
+	opRune      instOp = iota // match the literal character r
+	opAny                     // match any character except a path separator ('?' or part of '*')
+	opAnyChar                 // match any character, including a path separator (part of '**')
+	opDot                     // match a '.' at the start of a path component (part of '**')
+	opSeparator               // match a path separator, or the character r
+	opClass                   // match a character in a class
+	opRange                   // match a character in the ranges (but not a path separator)
+	opSplit                   // continue at x, and at y with a lower priority
+	opJump                    // continue at x
+	opSave                    // record the offset in the path in capture slot n
+	opNot                     // match any characters, except path separators, that sub doesn't match
+	opMatch                   // the whole path matched if there is no more path
+)
+
+// inst is an instruction in a program.
+type inst struct {
+	op      instOp
+	r       rune
+	class   []rune      // the class pattern for opClass
+	negated bool        // true if the class is negated
+	ranges  []runeRange // the ranges for opRange
+	x, y    int         // the targets of opSplit and opJump
+	n       int         // the capture slot for opSave
+	sub     *program    // the negated pattern list for opNot
+}
+
+// program is a compiled glob pattern. Each capture is a wildcard outside of a
+// brace expression or extended glob, or a whole brace expression or extended
+// glob. The text of each one is in
+// captures, and the start and end of the i-th one are recorded in slots 2×i
+// and 2×i+1.
+type program struct {
+	insts    []inst
+	captures []string
+
+	// machines holds the machines that ran the program before, so matching
+	// reuses their threads instead of allocating new ones
+	machines sync.Pool
+}
+
+// thread is a position in the program, and the captures recorded on the way
+// there. A thread at an opNot instruction is inside a negated pattern list,
+// and set is the state of the list's sub-program after the characters the
+// negation has consumed.
+type thread struct {
+	pc   int
+	set  *stateSet
+	caps []int
+}
+
+// programCompiler compiles a glob pattern into a program.
+type programCompiler struct {
+	config  *config
+	pattern []rune
+	pos     int
+	prog    *program
+
+	// literalEnds are the offsets of the '}' that close the braces around
+	// literal characters, innermost last
+	literalEnds []int
+}
+
+// compileProgram compiles a glob pattern with brace expressions or extended
+// globs into a program. Like Match, it assumes the glob pattern is valid, but
+// it doesn't panic if it isn't: an unclosed brace expression or extended glob
+// ends at the end of the pattern, and an unbalanced '}' is a literal
+// character.
+func (c *config) compileProgram(pattern []rune) *program {
+	pc := &programCompiler{config: c, pattern: pattern, prog: &program{}}
+	pc.sequence(0, 0, 0)
+	pc.emit(inst{op: opMatch})
+	return pc.prog
+}
+
+// emit appends an instruction to the program and returns its address.
+func (pc *programCompiler) emit(i inst) int {
+	pc.prog.insts = append(pc.prog.insts, i)
+	return len(pc.prog.insts) - 1
+}
+
+// capture compiles a wildcard, brace expression or extended glob that starts at
+// offset start, and records what it matches if it isn't inside a brace
+// expression or extended glob.
+func (pc *programCompiler) capture(depth, start int, compile func()) {
+	if depth > 0 {
+		compile()
+		return
+	}
+
+	n := len(pc.prog.captures)
+	pc.emit(inst{op: opSave, n: 2 * n})
+	compile()
+	pc.emit(inst{op: opSave, n: 2*n + 1})
+	pc.prog.captures = append(pc.prog.captures, encodeRunes(pc.pattern[start:pc.pos]))
+}
+
+// sequence compiles the glob pattern up to its end or, inside a brace
+// expression or extended glob, up to the separator or closing character that
+// ends the current alternative.
+func (pc *programCompiler) sequence(depth int, separator, closing rune) {
+	c := pc.config
+
+	for pc.pos < len(pc.pattern) {
+		start := pc.pos
+		token := pc.pattern[pc.pos]
+
+		switch {
+		case len(pc.literalEnds) > 0 && pc.pos == pc.literalEnds[len(pc.literalEnds)-1]:
+			pc.literalEnds = pc.literalEnds[:len(pc.literalEnds)-1]
+			pc.pos++
+			pc.emit(inst{op: opRune, r: token})
+		case depth > 0 && (token == separator || token == closing):
+			return
+		case token == c.escape:
+			// an escaped character is a literal, and a trailing escape
+			// character matches nothing
+			if pc.pos+1 < len(pc.pattern) {
+				pc.emit(inst{op: opRune, r: pc.pattern[pc.pos+1]})
+			}
+			pc.pos += 2
+			if pc.pos > len(pc.pattern) {
+				pc.pos = len(pc.pattern)
+			}
+		case c.isExtGlob(pc.pattern, pc.pos):
+			pc.capture(depth, start, func() {
+				pc.extGlob(depth + 1)
+			})
+		case token == '[':
+			pc.capture(depth, start, func() {
+				negated, class := c.getClass(pc.pattern[pc.pos:])
+				pc.pos += len(class)
+				pc.emit(inst{op: opClass, class: class, negated: negated})
+			})
+		case token == '?':
+			pc.capture(depth, start, func() {
+				pc.pos++
+				pc.emit(inst{op: opAny})
+			})
+		case token == '*':
+			pc.capture(depth, start, func() {
+				op := opAny
+				for pc.pos++; pc.pos < len(pc.pattern) && pc.pattern[pc.pos] == '*' && !c.isExtGlob(pc.pattern, pc.pos); pc.pos++ {
+					op = opAnyChar
+				}
+
+				if op == opAnyChar && (c.noDot || c.noHiddenDirs) {
+					pc.recursive()
+					return
+				}
+
+				// prefer to stop repeating, so the wildcard matches as few
+				// characters as it can
+				split := pc.emit(inst{op: opSplit})
+				repeat := pc.emit(inst{op: op})
+				pc.emit(inst{op: opJump, x: split})
+				pc.prog.insts[split].x = len(pc.prog.insts)
+				pc.prog.insts[split].y = repeat
+			})
+		case token == GlobSeparator || token == c.globSeparator:
+			pc.pos++
+			pc.emit(inst{op: opSeparator, r: token})
+		case token == '{' && c.braces && c.isBraceExpression(pc.pattern[pc.pos:]):
+			pc.capture(depth, start, func() {
+				pc.braces(depth + 1)
+			})
+		case token == '{' && c.braces:
+			// the braces are literal characters, but what's between them
+			// isn't necessarily
+			end, _ := c.braceEnd(pc.pattern[pc.pos:])
+			pc.literalEnds = append(pc.literalEnds, pc.pos+end)
+			pc.pos++
+			pc.emit(inst{op: opRune, r: token})
+		default:
+			pc.pos++
+			pc.emit(inst{op: opRune, r: token})
+		}
+	}
+}
+
+// recursive compiles a '**' wildcard that treats a '.' at the start of a path
+// component specially. Like '*', it can't match such a '.' if NoDot is set,
+// except that it may still cross a hidden directory, from its leading '.' to
+// the path separator after it, unless NoHiddenDirs is set. If the wildcard is
+// followed by a path separator, a hidden name at the end of its match is a
+// directory too.
+func (pc *programCompiler) recursive() {
+	c := pc.config
+
+	dirs := !c.noHiddenDirs
+	end := !c.noDot
+	if pc.pos < len(pc.pattern) && (pc.pattern[pc.pos] == GlobSeparator || pc.pattern[pc.pos] == c.globSeparator) {
+		end = dirs
+	}
+
+	// prefer to stop repeating, so the wildcard matches as few characters as
+	// it can
+	repeat := pc.emit(inst{op: opSplit, y: len(pc.prog.insts) + 1})
+
+	// any character, except a leading '.'
+	split := pc.emit(inst{op: opSplit, x: len(pc.prog.insts) + 1})
+	pc.emit(inst{op: opAnyChar})
+	pc.emit(inst{op: opJump, x: repeat})
+	pc.prog.insts[split].y = len(pc.prog.insts)
+
+	// or a hidden name, followed by a path separator if it's a directory that
+	// the wildcard crosses
+	var exits []int
+	if dirs || end {
+		pc.emit(inst{op: opDot})
+		name := pc.emit(inst{op: opSplit, y: len(pc.prog.insts) + 1})
+		pc.emit(inst{op: opAny})
+		pc.emit(inst{op: opJump, x: name})
+		pc.prog.insts[name].x = len(pc.prog.insts)
+
+		if dirs && end {
+			exits = append(exits, pc.emit(inst{op: opSplit, y: len(pc.prog.insts) + 1}))
+		} else if end {
+			exits = append(exits, pc.emit(inst{op: opJump}))
+		}
+
+		if dirs {
+			pc.emit(inst{op: opSeparator, r: GlobSeparator})
+			pc.emit(inst{op: opJump, x: repeat})
+		}
+	} else {
+		exits = append(exits, pc.emit(inst{op: opJump}))
+	}
+
+	pc.prog.insts[repeat].x = len(pc.prog.insts)
+	for _, exit := range exits {
+		pc.prog.insts[exit].x = len(pc.prog.insts)
+	}
+}
+
+// braces compiles a brace expression, which starts with the '{' at the current
+// position.
+func (pc *programCompiler) braces(depth int) {
+	end, hasComma := pc.config.braceEnd(pc.pattern[pc.pos:])
+	end += pc.pos
+
+	if !hasComma && end < len(pc.pattern) {
+		if r, isRange, valid := pc.config.parseBraceRange(pc.pattern[pc.pos+1 : end]); isRange && valid {
+			pc.pos = end + 1
+			if r.numeric {
+				pc.alternatives(r.numericAlternatives())
+			} else {
+				pc.emit(inst{op: opRange, ranges: []runeRange{{rune(r.lo), rune(r.hi)}}})
+			}
+			return
+		}
+	}
+
+	pc.list(depth, ',', '}')
+}
+
+// extGlob compiles an extended glob, which starts with the operator at the
+// current position. Repetitions, like wildcards, prefer to stop, so they
+// match as few characters as they can.
+func (pc *programCompiler) extGlob(depth int) {
+	operator := pc.pattern[pc.pos]
+	pc.pos++ // list skips the '('
+
+	switch operator {
+	case '@':
+		pc.list(depth, '|', ')')
+	case '?':
+		split := pc.emit(inst{op: opSplit, y: len(pc.prog.insts) + 1})
+		pc.list(depth, '|', ')')
+		pc.prog.insts[split].x = len(pc.prog.insts)
+	case '*':
+		split := pc.emit(inst{op: opSplit, y: len(pc.prog.insts) + 1})
+		pc.list(depth, '|', ')')
+		pc.emit(inst{op: opJump, x: split})
+		pc.prog.insts[split].x = len(pc.prog.insts)
+	case '+':
+		body := len(pc.prog.insts)
+		pc.list(depth, '|', ')')
+		pc.emit(inst{op: opSplit, x: len(pc.prog.insts) + 1, y: body})
+	case '!':
+		sub := &programCompiler{config: pc.config, pattern: pc.pattern, pos: pc.pos, prog: &program{}}
+		sub.list(depth, '|', ')')
+		sub.emit(inst{op: opMatch})
+		pc.pos = sub.pos
+		pc.emit(inst{op: opNot, sub: sub.prog})
+	}
+}
+
+// list compiles the alternatives of a brace expression or extended glob,
+// which starts with the '{' or '(' at the current position. The alternatives
+// are separated by separator and end with closing.
+func (pc *programCompiler) list(depth int, separator, closing rune) {
+	// Each alternative is preceded by a split to it and to the next one, and
+	// followed by a jump to the end of the list. The last alternative has no
+	// next one, so its split becomes a jump to it.
+	var split int
+	var jumps []int
+	for {
+		pc.pos++ // skip the '{', '(' or separator
+		split = pc.emit(inst{op: opSplit, x: len(pc.prog.insts) + 1})
+		pc.sequence(depth, separator, closing)
+		jumps = append(jumps, pc.emit(inst{op: opJump}))
+		pc.prog.insts[split].y = len(pc.prog.insts)
+
+		if pc.pos >= len(pc.pattern) || pc.pattern[pc.pos] == closing {
+			break
+		}
+	}
+
+	pc.prog.insts[split] = inst{op: opJump, x: split + 1}
+	for _, jump := range jumps {
+		pc.prog.insts[jump].x = len(pc.prog.insts)
+	}
+
+	if pc.pos < len(pc.pattern) {
+		pc.pos++ // skip the closing character
+	}
+}
+
+// alternatives compiles alternative sequences of character ranges, such as
+// those that match a numeric range.
+func (pc *programCompiler) alternatives(alternatives [][]runeRange) {
+	var jumps []int
+	for i, alternative := range alternatives {
+		split := -1
+		if i < len(alternatives)-1 {
+			split = pc.emit(inst{op: opSplit, x: len(pc.prog.insts) + 1})
+		}
+
+		for _, r := range alternative {
+			pc.emit(inst{op: opRange, ranges: []runeRange{r}})
+		}
+
+		if split >= 0 {
+			jumps = append(jumps, pc.emit(inst{op: opJump}))
+			pc.prog.insts[split].y = len(pc.prog.insts)
+		}
+	}
+
+	for _, jump := range jumps {
+		pc.prog.insts[jump].x = len(pc.prog.insts)
+	}
+}
+
+// step returns true if the instruction matches the character in the path.
+// leading is true if the character is at the start of a path component, where
+// only a literal matches a '.' if NoDot is set.
+func (c *config) step(in *inst, value rune, leading bool) bool {
+	if leading && value == '.' && (c.noDot || in.op == opAnyChar && c.noHiddenDirs) {
+		switch in.op {
+		case opAny, opAnyChar, opClass, opRange:
+			return false
+		}
+	}
+
+	switch in.op {
+	case opRune:
+		return c.equal(in.r, value)
+	case opAny:
+		return !c.isSeparator(value)
+	case opAnyChar:
+		return true
+	case opDot:
+		return leading && value == '.'
+	case opSeparator:
+		return c.isSeparator(value) || value == in.r
+	case opClass:
+		return c.matchClass(in.class, value, in.negated)
+	case opRange:
+		if c.isSeparator(value) {
+			return false
+		}
+		for _, r := range in.ranges {
+			if c.inRange(value, r.lo, r.hi) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// stateSet is a set of threads of a sub-program, which is a state of the
+// deterministic automaton for a negated pattern list. The sets are interned, so
+// two threads in the same state share the same set. accepting is true if one
+// of the threads is at the end of the sub-program, that is, if the list
+// matches the characters consumed so far.
+type stateSet struct {
+	id        int
+	threads   []thread
+	accepting bool
+}
+
+// setStep is a transition from a stateSet on a character.
+type setStep struct {
+	set   *stateSet
+	value rune
+}
+
+// negation is a thread inside a negated pattern list, which is identified by
+// its instruction and state.
+type negation struct {
+	pc  int
+	set *stateSet
+}
+
+// threadList is an ordered list of threads of a program, with at most one
+// thread for each instruction, or for each state of a negation.
+type threadList struct {
+	prog    *program
+	threads []thread
+
+	// marks[pc] and negations[n] are the generation of the last list pc or n
+	// was added to, so a thread is added to each list at most once.
+	marks      []int
+	negations  map[negation]int
+	generation int
+}
+
+// newThreadList returns an empty list of threads of the program.
+func newThreadList(p *program) *threadList {
+	return &threadList{prog: p, marks: make([]int, len(p.insts)), negations: map[negation]int{}}
+}
+
+// reset empties the list so it can be reused.
+func (l *threadList) reset() {
+	l.threads = l.threads[:0]
+	l.generation++
+}
+
+// machine simulates a program. It builds the states of the negated pattern
+// lists as they are needed, and remembers them and their transitions for as
+// long as it's reused. They depend only on the program and the configuration,
+// which are the same each time.
+type machine struct {
+	config *config
+	sets   map[*program]map[string]*stateSet
+	starts map[*program]*stateSet
+	steps  map[setStep]*stateSet
+	lists  map[*program]*threadList
+
+	// current and next are the threads of the program the machine runs, and
+	// path is the path being matched, decoded to runes
+	current, next *threadList
+	path          []rune
+}
+
+// newMachine returns a machine that runs the program using the configuration.
+func newMachine(c *config, p *program) *machine {
+	return &machine{
+		config:  c,
+		sets:    map[*program]map[string]*stateSet{},
+		starts:  map[*program]*stateSet{},
+		steps:   map[setStep]*stateSet{},
+		lists:   map[*program]*threadList{},
+		current: newThreadList(p),
+		next:    newThreadList(p),
+	}
+}
+
+// machine returns a machine for the program that's ready to match the path,
+// reusing one that ran the program before if there is one. The machine must be
+// put back in p.machines when the match is done.
+func (p *program) machine(c *config, path string) *machine {
+	m, _ := p.machines.Get().(*machine)
+	if m == nil {
+		m = newMachine(c, p)
+	}
+
+	m.path = c.appendRunes(m.path[:0], path)
+	return m
+}
+
+// add adds a thread to the list, following jumps, splits and saves, and
+// entering and leaving negations, until it reaches instructions that consume a
+// character. pos is the offset in the path, for saves.
+func (m *machine) add(l *threadList, t thread, pos int) {
+	in := &l.prog.insts[t.pc]
+
+	if in.op == opNot {
+		if t.set == nil {
+			t.set = m.start(in.sub)
+		}
+
+		key := negation{t.pc, t.set}
+		if l.negations[key] == l.generation {
+			return
+		}
+		l.negations[key] = l.generation
+
+		// the negation may end here if the list doesn't match what it
+		// consumed, and it prefers to, like a wildcard
+		if !t.set.accepting {
+			m.add(l, thread{pc: t.pc + 1, caps: t.caps}, pos)
+		}
+		l.threads = append(l.threads, t)
+		return
+	}
+
+	if l.marks[t.pc] == l.generation {
+		return
+	}
+	l.marks[t.pc] = l.generation
+
+	switch in.op {
+	case opJump:
+		m.add(l, thread{pc: in.x, caps: t.caps}, pos)
+	case opSplit:
+		m.add(l, thread{pc: in.x, caps: t.caps}, pos)
+		m.add(l, thread{pc: in.y, caps: t.caps}, pos)
+	case opSave:
+		caps := t.caps
+		if caps != nil {
+			caps = make([]int, len(t.caps))
+			copy(caps, t.caps)
+			caps[in.n] = pos
+		}
+		m.add(l, thread{pc: t.pc + 1, caps: caps}, pos)
+	default:
+		l.threads = append(l.threads, t)
+	}
+}
+
+// advance adds the threads that follow t to the list, if t consumes the
+// character at offset pos in the path. leading is true if the character is at
+// the start of a path component.
+func (m *machine) advance(l *threadList, t thread, value rune, pos int, leading bool) {
+	c := m.config
+	in := &l.prog.insts[t.pc]
+
+	switch {
+	case in.op == opNot:
+		// a negation never starts with a '.' that only a literal matches,
+		// and it never crosses a path separator, so the characters of its
+		// sub-program are never leading ones
+		if !c.isSeparator(value) && !(leading && value == '.' && c.noDot) {
+			m.add(l, thread{pc: t.pc, set: m.step(in.sub, t.set, value), caps: t.caps}, pos+1)
+		}
+	case c.step(in, value, leading):
+		m.add(l, thread{pc: t.pc + 1, caps: t.caps}, pos+1)
+	}
+}
+
+// list returns an empty list of threads of a sub-program. The list is reused,
+// so it's only valid until list is called again for the same sub-program.
+func (m *machine) list(p *program) *threadList {
+	l := m.lists[p]
+	if l == nil {
+		l = newThreadList(p)
+		m.lists[p] = l
+	}
+
+	l.reset()
+	return l
+}
+
+// start returns the state of a sub-program before it has consumed any
+// characters.
+func (m *machine) start(p *program) *stateSet {
+	if set := m.starts[p]; set != nil {
+		return set
+	}
+
+	l := m.list(p)
+	m.add(l, thread{}, 0)
+	set := m.intern(p, l.threads)
+	m.starts[p] = set
+	return set
+}
+
+// step returns the state of a sub-program after it consumes a character in
+// the given state.
+func (m *machine) step(p *program, set *stateSet, value rune) *stateSet {
+	key := setStep{set, value}
+	if next := m.steps[key]; next != nil {
+		return next
+	}
+
+	l := m.list(p)
+	for _, t := range set.threads {
+		m.advance(l, t, value, 0, false)
+	}
+
+	next := m.intern(p, l.threads)
+	m.steps[key] = next
+	return next
+}
+
+// intern returns the state of a sub-program with the given threads, creating
+// it if it's new. The order of the threads doesn't matter, because the threads
+// of a sub-program record no captures.
+func (m *machine) intern(p *program, threads []thread) *stateSet {
+	set := &stateSet{threads: append([]thread(nil), threads...)}
+	sort.Slice(set.threads, func(i, j int) bool {
+		a, b := set.threads[i], set.threads[j]
+		return a.pc < b.pc || a.pc == b.pc && a.set.ident() < b.set.ident()
+	})
+
+	var key []byte
+	for _, t := range set.threads {
+		key = strconv.AppendInt(key, int64(t.pc), 10)
+		key = append(key, ':')
+		key = strconv.AppendInt(key, int64(t.set.ident()), 10)
+		key = append(key, ',')
+		set.accepting = set.accepting || p.insts[t.pc].op == opMatch
+	}
+
+	sets := m.sets[p]
+	if sets == nil {
+		sets = map[string]*stateSet{}
+		m.sets[p] = sets
+	}
+
+	if existing := sets[string(key)]; existing != nil {
+		return existing
+	}
+
+	set.id = len(sets) + 1
+	sets[string(key)] = set
+	return set
+}
+
+// ident returns the id of the set, or zero if there is no set.
+func (s *stateSet) ident() int {
+	if s == nil {
+		return 0
+	}
+
+	return s.id
+}
+
+// run matches the whole path against the program. It returns true and the
+// length of the path if it matched, and false and the length of the longest
+// prefix of the path that some alternative matched otherwise. If caps isn't
+// nil, it must have two slots for each capture, and on success they hold the
+// offsets in the path where each capture starts and ends. When there's more
+// than one way to match the path, the captures are those of the alternative
+// that comes first in the glob pattern, where each wildcard matches as few
+// characters as it can, starting with the leftmost one.
+func (p *program) run(c *config, pathString string, caps []int) (bool, int) {
+	var count int
+	var matched bool
+
+	m := p.machine(c, pathString)
+	defer p.machines.Put(m)
+	current, next, path := m.current, m.next, m.path
+
+	current.reset()
+	m.add(current, thread{caps: caps}, 0)
+
+	for pos := 0; len(current.threads) > 0; pos++ {
+		count = pos
+		next.reset()
+
+		for _, t := range current.threads {
+			if p.insts[t.pc].op == opMatch {
+				if pos == len(path) {
+					// threads after this one have a lower priority
+					matched = true
+					copy(caps, t.caps)
+					break
+				}
+				continue
+			}
+
+			if pos < len(path) {
+				m.advance(next, t, path[pos], pos, pos == 0 || c.isSeparator(path[pos-1]))
+			}
+		}
+
+		if matched || pos == len(path) {
+			break
+		}
+		current, next = next, current
+	}
+
+	return matched, count
+}
+
+// partial returns true if the program could match the path followed by more
+// characters, that is, if a thread that hasn't reached the end of the program
+// is still alive after the whole path.
+func (p *program) partial(c *config, pathString string) bool {
+	m := p.machine(c, pathString)
+	defer p.machines.Put(m)
+	current, next, path := m.current, m.next, m.path
+
+	current.reset()
+	m.add(current, thread{}, 0)
+
+	for pos := 0; pos < len(path) && len(current.threads) > 0; pos++ {
+		next.reset()
+		for _, t := range current.threads {
+			if p.insts[t.pc].op != opMatch {
+				m.advance(next, t, path[pos], pos, pos == 0 || c.isSeparator(path[pos-1]))
+			}
+		}
+		current, next = next, current
+	}
+
+	for _, t := range current.threads {
+		if p.insts[t.pc].op != opMatch {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,10 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+//go:build race
+
+package glob
+
+// raceEnabled is true if the tests run with the race detector, which drops
+// items from a sync.Pool at random, so a pool can't be counted on to reuse
+// memory.
+const raceEnabled = true
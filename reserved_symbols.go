@@ -5,10 +5,54 @@ package glob
 type reservedSymbolSet map[rune]struct{}
 
 var (
-	exists          = struct{}{}
-	reservedSymbols reservedSymbolSet
+	exists = struct{}{}
+
+	// Per the "most UNIX file systems" row in the "Comparison of filename
+	// limitations" table at
+	// https://en.wikipedia.org/wiki/Filename#Comparison_of_filename_limitations
+	// the reserved symbols are nul (0x00) and /. However, we need '/' for glob
+	// pattern matching, so nul is the only reserved symbol.
+	unixReservedSymbols = reservedSymbolSet{
+		rune(0x00): exists,
+	}
+
+	// Per the NTFS row in the "Comparison of filename limitations" table at
+	// https://en.wikipedia.org/wiki/Filename#Comparison_of_filename_limitations
+	// the reserved symbols are the ascii control codes (0x00-0x1F and 0x7F) and
+	// these characters: " * / : < > ? \ |. However, we need '\', '/', '?', and
+	// '*' for glob patterns:
+	//
+	//   - '\': is used as a path separator and an escape character so glob
+	//     patterns can contain literal characters that would otherwise be
+	//     interpreted as glob patterns ('[', ']', and '!' in a class range).
+	//   - '/': is a alternate path separator, because glob patterns are more
+	//     fun and flexible that way.
+	//   - '?': match any single character except a path separator
+	//   - '*': is used to match either zero or more characters except a path
+	//     separator (a single '*'), or zero or more characters including any
+	//     and all path separators (a sequence of two or more asterisks).
+	//
+	// The Windows dialect also allows ':' in a drive designator, such as "C:",
+	// at the start of a glob pattern.
+	windowsReservedSymbols = newWindowsReservedSymbols()
 )
 
+func newWindowsReservedSymbols() reservedSymbolSet {
+	symbols := make(reservedSymbolSet)
+	// ascii control codes 0x00 - 0x1F are reserved
+	for i := 0; i < 0x20; i++ {
+		symbols[rune(i)] = exists
+	}
+	symbols[rune(0x7F)] = exists
+	symbols[rune('"')] = exists
+	symbols[rune(':')] = exists
+	symbols[rune('<')] = exists
+	symbols[rune('>')] = exists
+	symbols[rune('|')] = exists
+
+	return symbols
+}
+
 // hasReservedSymbol returns true if the string contains a character that is a
 // member of reservedSymbols (a set of symbols not allowed glob patterns).
 func hasReservedSymbol(pattern []rune) (bool, int) {
@@ -26,3 +70,10 @@ func isReservedSymbol(symbol rune) bool {
 	_, ok := reservedSymbols[symbol]
 	return ok
 }
+
+// isReservedSymbol returns true if the symbol is contained in the set of
+// symbols not allowed in a file path in the dialect being used.
+func (c *config) isReservedSymbol(symbol rune) bool {
+	_, ok := c.reservedSymbols[symbol]
+	return ok
+}
@@ -4,12 +4,6 @@
 
 package glob
 
-// Per the "most UNIX file systems" row in the "Comparison of filename
-// limitations" table at
-// https://en.wikipedia.org/wiki/Filename#Comparison_of_filename_limitations
-// the reserved symbols are nul (0x00) and /. However, we need '/' for glob
-// pattern matching, so nul is the only reserved symbol.
-func init() {
-	reservedSymbols = make(reservedSymbolSet)
-	reservedSymbols[rune(0x00)] = exists
-}
+// The native dialect on Linux uses the reserved symbols of most UNIX file
+// systems.
+var reservedSymbols = unixReservedSymbols
@@ -0,0 +1,71 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"testing"
+)
+
+// Verify the reserved symbols of each dialect, whatever the operating system.
+func TestDialectReservedSymbols(t *testing.T) {
+	testIO := []struct {
+		name     string
+		dialect  Dialect
+		symbol   rune
+		expected bool
+	}{
+		{"unix: null is reserved", UnixDialect, 0x00, true},
+		{"unix: colon is not reserved", UnixDialect, ':', false},
+		{"unix: tab is not reserved", UnixDialect, '\t', false},
+		{"windows: null is reserved", WindowsDialect, 0x00, true},
+		{"windows: unit separator is reserved", WindowsDialect, 0x1F, true},
+		{"windows: del is reserved", WindowsDialect, 0x7F, true},
+		{"windows: double quote is reserved", WindowsDialect, '"', true},
+		{"windows: colon is reserved", WindowsDialect, ':', true},
+		{"windows: less than is reserved", WindowsDialect, '<', true},
+		{"windows: greater than is reserved", WindowsDialect, '>', true},
+		{"windows: vertical bar is reserved", WindowsDialect, '|', true},
+		{"windows: space is not reserved", WindowsDialect, ' ', false},
+		{"windows: backslash is not reserved", WindowsDialect, '\\', false},
+	}
+
+	for _, test := range testIO {
+		t.Run(test.name, func(t *testing.T) {
+			c := Options{Dialect: test.dialect}.config()
+			if actual := c.isReservedSymbol(test.symbol); actual != test.expected {
+				t.Errorf("Expected: %t. Actual: %t.", test.expected, actual)
+			}
+		})
+	}
+}
+
+// Verify isReservedName recognizes the Windows device names.
+func TestIsReservedName(t *testing.T) {
+	testIO := []struct {
+		name     string
+		expected bool
+	}{
+		{"CON", true},
+		{"con", true},
+		{"Nul.txt", true},
+		{"aux.tar.gz", true},
+		{"PRN  ", true},
+		{"COM9", true},
+		{"com¹", true},
+		{"LPT1.log", true},
+		{"COM0", false},
+		{"COM10", false},
+		{"CONSOLE", false},
+		{"ACON", false},
+		{" CON", false},
+		{"", false},
+	}
+
+	for _, test := range testIO {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := isReservedName(test.name); actual != test.expected {
+				t.Errorf("Expected: %t. Actual: %t.", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// TemplateError describes a problem found in a rewrite template.
+type TemplateError struct {
+	Template   string // the template that has the error
+	Offset     int    // zero-based rune offset of the reference in Template
+	ByteOffset int    // zero-based byte offset of the reference in Template
+	Kind       error  // the kind of error, such as ErrGlobUnknownWildcard
+}
+
+// Error satisfies the error interface.
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("%s at offset %d of template %q", e.Kind, e.Offset, e.Template)
+}
+
+// Unwrap returns the kind of error, so errors.Is and errors.As can see it.
+func (e *TemplateError) Unwrap() error {
+	return e.Kind
+}
+
+// templatePart is either literal text or a reference to a capture. Capture 0
+// is the entire path and capture n is what the n-th wildcard consumed.
+type templatePart struct {
+	literal string
+	capture int
+}
+
+// Rewrite matches a path against a glob pattern and, if the path matches,
+// returns the template with each reference to a wildcard replaced by what
+// that wildcard consumed in the path. It returns false if the path doesn't
+// match, and an error if the pattern or the template is invalid.
+//
+// In the template, $n or ${n} is replaced by what the n-th wildcard ('?', '*',
+// '**' or a class) in the glob pattern consumed, counting from 1, and $0 is
+// replaced by the entire path. Use $$ for a literal dollar sign. For example,
+// rewriting "src/a/b/x.proto" with the pattern "src/**/*.proto" and the
+// template "gen/$1/$2.pb.go" returns "gen/a/b/x.pb.go".
+func Rewrite(fromPattern, toTemplate, path string) (string, bool, error) {
+	p, err := Compile(fromPattern)
+	if err != nil {
+		return "", false, err
+	}
+
+	return p.Rewrite(toTemplate, path)
+}
+
+// Rewrite is like the package-level Rewrite function, but it matches the path
+// against the compiled glob pattern.
+func (p *Pattern) Rewrite(template, path string) (string, bool, error) {
+	parts, err := parseTemplate(template, p.wildcardCount())
+	if err != nil {
+		return "", false, err
+	}
+
+	captures, matched := p.MatchCaptures(path)
+	if !matched {
+		return "", false, nil
+	}
+
+	var b strings.Builder
+	for _, part := range parts {
+		switch {
+		case part.capture < 0:
+			b.WriteString(part.literal)
+		case part.capture == 0:
+			b.WriteString(path)
+		default:
+			c := captures[part.capture-1]
+			b.WriteString(path[c.ByteStart:c.ByteEnd])
+		}
+	}
+
+	return b.String(), true, nil
+}
+
+// wildcardCount returns the number of wildcards in the glob pattern, which is
+// the number of captures made by a successful match.
+func (p *Pattern) wildcardCount() int {
+	if p.prog != nil {
+		return len(p.prog.captures)
+	}
+
+	var count int
+
+	for _, c := range p.chunks {
+		if c.kind != patternSimple {
+			count++
+		}
+		count += len(p.config.wildcards(c.head))
+	}
+
+	return count
+}
+
+// parseTemplate breaks a rewrite template down into literal text and
+// references to captures. It returns a *TemplateError if a reference is
+// malformed or refers to a wildcard beyond the given number of wildcards.
+func parseTemplate(template string, wildcards int) ([]templatePart, error) {
+	var parts []templatePart
+	var literal strings.Builder
+
+	fail := func(offset int, kind globError) error {
+		return &TemplateError{
+			Template:   template,
+			Offset:     utf8.RuneCountInString(template[:offset]),
+			ByteOffset: offset,
+			Kind:       kind,
+		}
+	}
+
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' {
+			literal.WriteByte(template[i])
+			continue
+		}
+
+		start := i
+		i++
+		if i < len(template) && template[i] == '$' {
+			literal.WriteByte('$')
+			continue
+		}
+
+		braced := i < len(template) && template[i] == '{'
+		if braced {
+			i++
+		}
+
+		digits := i
+		for ; i < len(template) && template[i] >= '0' && template[i] <= '9'; i++ {
+		}
+		if i == digits || braced && (i == len(template) || template[i] != '}') {
+			return nil, fail(start, ErrGlobBadReference)
+		}
+
+		var capture int
+		for _, digit := range template[digits:i] {
+			capture = capture*10 + int(digit-'0')
+			if capture > wildcards {
+				return nil, fail(start, ErrGlobUnknownWildcard)
+			}
+		}
+
+		// leave i at the last character of the reference
+		if !braced {
+			i--
+		}
+
+		if literal.Len() > 0 {
+			parts = append(parts, templatePart{literal: literal.String(), capture: -1})
+			literal.Reset()
+		}
+		parts = append(parts, templatePart{capture: capture})
+	}
+
+	if literal.Len() > 0 {
+		parts = append(parts, templatePart{literal: literal.String(), capture: -1})
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,135 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Verify Rewrite substitutes captures into templates.
+func TestRewrite(t *testing.T) {
+	// sep converts a path written with '/' to one using the path separator
+	sep := func(path string) string {
+		return strings.ReplaceAll(path, "/", SeparatorString)
+	}
+
+	testIO := []struct {
+		pattern  string
+		template string
+		path     string
+		expected string
+		matched  bool
+		err      error
+		offset   int
+	}{
+		{
+			pattern:  "src/**/*.proto",
+			template: "gen/$1/$2.pb.go",
+			path:     "src/a/b/x.proto",
+			expected: "gen/a" + SeparatorString + "b/x.pb.go",
+			matched:  true,
+		},
+		{
+			pattern:  "src/**/*.proto",
+			template: "gen/${1}/${2}_pb.go",
+			path:     "src/a/x.proto",
+			expected: "gen/a/x_pb.go",
+			matched:  true,
+		},
+		{
+			pattern:  "*.txt",
+			template: "$$1 $0 $1",
+			path:     "世界.txt",
+			expected: "$1 世界.txt 世界",
+			matched:  true,
+		},
+		{
+			pattern:  "?[0-9]*",
+			template: "$3-$2-$1",
+			path:     "a1bc",
+			expected: "bc-1-a",
+			matched:  true,
+		},
+		{
+			pattern:  "no wildcards",
+			template: "constant",
+			path:     "no wildcards",
+			expected: "constant",
+			matched:  true,
+		},
+		{
+			pattern:  "*.go",
+			template: "$1.o",
+			path:     "main.c",
+		},
+		{
+			pattern:  "*.go",
+			template: "$2.o",
+			path:     "main.go",
+			err:      ErrGlobUnknownWildcard,
+			offset:   0,
+		},
+		{
+			pattern:  "*.go",
+			template: "bin/$99999999999999999999",
+			path:     "main.go",
+			err:      ErrGlobUnknownWildcard,
+			offset:   4,
+		},
+		{
+			pattern:  "*.go",
+			template: "bin/$x",
+			path:     "main.go",
+			err:      ErrGlobBadReference,
+			offset:   4,
+		},
+		{
+			pattern:  "*.go",
+			template: "界/${1",
+			path:     "main.go",
+			err:      ErrGlobBadReference,
+			offset:   2,
+		},
+		{
+			pattern:  "*.go",
+			template: "$",
+			path:     "main.go",
+			err:      ErrGlobBadReference,
+			offset:   0,
+		},
+		{
+			pattern:  "[",
+			template: "$1",
+			path:     "[",
+			err:      ErrGlobTruncated,
+		},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			actual, matched, err := Rewrite(test.pattern, test.template, sep(test.path))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Test %s (%s, %s): Expected error %v. Actual %v.", name, test.pattern, test.template, test.err, err)
+			}
+
+			var templateErr *TemplateError
+			if errors.As(err, &templateErr) && templateErr.Offset != test.offset {
+				t.Errorf("Test %s (%s): Expected offset %d. Actual %d.", name, test.template, test.offset, templateErr.Offset)
+			}
+
+			if matched != test.matched {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.matched, matched)
+			}
+
+			if actual != test.expected {
+				t.Errorf("Test %s (%s, %s, %s): Expected %q. Actual %q.", name, test.pattern, test.template, test.path, test.expected, actual)
+			}
+		})
+	}
+}
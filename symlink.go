@@ -0,0 +1,176 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// SymlinkPolicy selects the symbolic links a Walker follows.
+type SymlinkPolicy int
+
+const (
+	// FollowRoot follows the base directory of the glob pattern if it's a
+	// symbolic link, as find -H does, but no link inside of it. It's how Glob
+	// walks a file system.
+	FollowRoot SymlinkPolicy = iota
+
+	// FollowNever follows no symbolic links, not even the base directory of
+	// the glob pattern or a directory it's in, as find -P does. A link is
+	// matched as a file.
+	FollowNever
+
+	// FollowAlways follows every symbolic link, as find -L does, so a link to
+	// a directory is matched and walked like the directory. A link to one of
+	// the directories it's in is matched, but not walked, since that would
+	// walk the same directories forever. A link the walk would follow that
+	// points to nothing, or to itself, is reported as an error that wraps
+	// ErrBrokenSymlink (see Walker.OnBrokenSymlink), and the walk goes on.
+	FollowAlways
+)
+
+// String returns the name of the policy.
+func (s SymlinkPolicy) String() string {
+	switch s {
+	case FollowRoot:
+		return "root"
+	case FollowNever:
+		return "never"
+	case FollowAlways:
+		return "always"
+	}
+
+	return "SymlinkPolicy(" + strconv.Itoa(int(s)) + ")"
+}
+
+// maxSymlinks is the most symbolic links realPath follows in one name, which
+// is the limit Linux has.
+const maxSymlinks = 40
+
+// isSymlink returns true if the file is a symbolic link.
+func isSymlink(fsys fs.FS, name string) bool {
+	info, err := fs.Lstat(fsys, name)
+	return err == nil && info.Mode()&fs.ModeSymlink != 0
+}
+
+// hasSymlink returns true if the file, or any directory in its name, is a
+// symbolic link.
+func hasSymlink(fsys fs.FS, name string) bool {
+	for ; name != "."; name = path.Dir(name) {
+		if isSymlink(fsys, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve returns the entry for the file a symbolic link points to, and true
+// if it's a directory the walk should go into. If the link points to nothing,
+// or to itself, the error wraps ErrBrokenSymlink.
+func (wk *walk) resolve(name string) (fs.DirEntry, bool, error) {
+	info, err := fs.Stat(wk.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ELOOP) {
+		return nil, false, &fs.PathError{Op: "stat", Path: name, Err: ErrBrokenSymlink}
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return fs.FileInfoToDirEntry(info), info.IsDir() && wk.isNewDir(name, info), nil
+}
+
+// isNewDir returns true if the directory isn't one of the directories its name
+// is in, so walking it won't walk the same directories forever. It returns
+// false if the directory can't be told apart from them.
+func (wk *walk) isNewDir(name string, info fs.FileInfo) bool {
+	id, ok := statID(wk.fsys, name, info)
+	if !ok {
+		return false
+	}
+
+	for dir := path.Dir(name); ; dir = path.Dir(dir) {
+		dirInfo, err := fs.Stat(wk.fsys, dir)
+		if err != nil {
+			return false
+		}
+
+		if dirID, ok := statID(wk.fsys, dir, dirInfo); !ok || dirID == id {
+			return false
+		}
+
+		if dir == "." {
+			return true
+		}
+	}
+}
+
+// fileID identifies a file, so two names of the same file have the same ID,
+// and the names of different files don't. It's the device and inode numbers
+// of the file where there are some, and otherwise the name of the file with
+// the symbolic links in it resolved.
+type fileID struct {
+	dev, ino uint64
+	path     string
+}
+
+// statID returns the ID of the file with the name and information.
+func statID(fsys fs.FS, name string, info fs.FileInfo) (fileID, bool) {
+	if dev, ino, ok := deviceInode(info); ok {
+		return fileID{dev: dev, ino: ino}, true
+	}
+
+	resolved, ok := realPath(fsys, name)
+	return fileID{path: resolved}, ok
+}
+
+// realPath returns the name of a file with each symbolic link in it replaced
+// by the name it points to. It returns false if a link points outside of the
+// file system, or if there are too many links to follow.
+func realPath(fsys fs.FS, name string) (string, bool) {
+	resolved, rest := ".", name
+	for links := 0; rest != ""; {
+		var elem string
+		elem, rest, _ = strings.Cut(rest, "/")
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if resolved == "." {
+				return "", false
+			}
+			resolved = path.Dir(resolved)
+			continue
+		}
+
+		next := joinName(resolved, elem)
+		info, err := fs.Lstat(fsys, next)
+		if err != nil {
+			return "", false
+		}
+
+		if info.Mode()&fs.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		links++
+		target, err := fs.ReadLink(fsys, next)
+		if err != nil || links > maxSymlinks || path.IsAbs(target) {
+			return "", false
+		}
+
+		// the target is relative to the directory the link is in, which is
+		// the name resolved so far
+		if rest != "" {
+			target += "/" + rest
+		}
+		rest = target
+	}
+
+	return resolved, true
+}
@@ -0,0 +1,91 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// Verify links to directories are followed in the operating system's file
+// system, and links back to the directories they are in are found by their
+// device and inode numbers, even if they are absolute.
+func TestWalkerFollowOSSymlinks(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/b", "c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "a/b/x.yaml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	links := map[string]string{
+		"c/a":    "../a",
+		"a/b/up": "..",
+		"a/abs":  filepath.Join(root, "a"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fsys := os.DirFS(root)
+	info, err := os.Stat(filepath.Join(root, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := deviceInode(info); !ok {
+		t.Errorf("Expected the device and inode numbers of %q", info.Name())
+	}
+
+	w := Walker{FollowSymlinks: FollowAlways}
+	matches, err := w.Glob(context.Background(), fsys, "**/*.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	expected := []string{"a/b/x.yaml", "c/a/b/x.yaml"}
+	if fmt.Sprint(matches) != fmt.Sprint(expected) {
+		t.Errorf("Expected %q. Actual %q.", expected, matches)
+	}
+
+	// links that point to nothing, or to themselves, are broken, and reported
+	// without stopping the walk
+	for name, target := range map[string]string{"c/gone.yaml": "nothing", "c/gone": "nothing", "c/spin.yaml": "spin.yaml"} {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var broken []string
+	w.OnBrokenSymlink = func(err error) error {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) && errors.Is(err, ErrBrokenSymlink) {
+			broken = append(broken, pathErr.Path)
+		}
+		return nil
+	}
+
+	matches, err = w.Glob(context.Background(), fsys, "**/*.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if fmt.Sprint(matches) != fmt.Sprint(expected) {
+		t.Errorf("Expected %q. Actual %q.", expected, matches)
+	}
+
+	sort.Strings(broken)
+	if expected := []string{"c/gone", "c/gone.yaml", "c/spin.yaml"}; fmt.Sprint(broken) != fmt.Sprint(expected) {
+		t.Errorf("Expected broken links %q. Actual %q.", expected, broken)
+	}
+}
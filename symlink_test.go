@@ -0,0 +1,303 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+// symlink returns a symbolic link to the target.
+func symlink(target string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(target), Mode: fs.ModeSymlink}
+}
+
+// linkFS is a tree of files with symbolic links in it, two of which point to
+// directories they are in.
+var linkFS = fstest.MapFS{
+	"data/a.yaml":     {},
+	"data/sub/b.yaml": {},
+	"conf/c.yaml":     {},
+	"conf/d.yaml":     symlink("../data/a.yaml"),
+	"conf/data":       symlink("../data"),
+	"conf/loop":       symlink(".."),
+	"conf/self":       symlink("."),
+	"top":             symlink("conf"),
+}
+
+func TestWalkerFollowSymlinks(t *testing.T) {
+	testIO := []struct {
+		follow   SymlinkPolicy
+		pattern  string
+		expected []string
+	}{
+		{FollowRoot, "**/*.yaml", []string{"conf/c.yaml", "conf/d.yaml", "data/a.yaml", "data/sub/b.yaml"}},
+		{FollowNever, "**/*.yaml", []string{"conf/c.yaml", "conf/d.yaml", "data/a.yaml", "data/sub/b.yaml"}},
+		{FollowAlways, "**/*.yaml", []string{"conf/c.yaml", "conf/d.yaml", "conf/data/a.yaml", "conf/data/sub/b.yaml", "data/a.yaml", "data/sub/b.yaml", "top/c.yaml", "top/d.yaml", "top/data/a.yaml", "top/data/sub/b.yaml"}},
+		{FollowRoot, "**/", []string{"conf", "data", "data/sub"}},
+		{FollowAlways, "**/", []string{"conf", "conf/data", "conf/data/sub", "conf/loop", "conf/self", "data", "data/sub", "top", "top/data", "top/data/sub", "top/loop", "top/self"}},
+
+		// only the base directory is followed at the root
+		{FollowRoot, "top/*.yaml", []string{"top/c.yaml", "top/d.yaml"}},
+		{FollowNever, "top/*.yaml", nil},
+		{FollowAlways, "top/*.yaml", []string{"top/c.yaml", "top/d.yaml"}},
+		{FollowRoot, "top/data/*.yaml", []string{"top/data/a.yaml"}},
+		{FollowNever, "top/data/*.yaml", nil},
+		{FollowAlways, "top/data/*.yaml", []string{"top/data/a.yaml"}},
+		{FollowRoot, "conf/data/*.yaml", []string{"conf/data/a.yaml"}},
+		{FollowNever, "conf/data/*.yaml", nil},
+
+		// links to the directories they are in are matched, but not walked
+		{FollowAlways, "conf/*/*/", []string{"conf/data/sub"}},
+		{FollowAlways, "*/loop/*", nil},
+		{FollowAlways, "*/loop", []string{"conf/loop", "top/loop"}},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			for _, workers := range []int{1, 4} {
+				w := Walker{Workers: workers, FollowSymlinks: test.follow}
+				matches, err := w.Glob(context.Background(), linkFS, test.pattern)
+				if err != nil {
+					t.Fatalf("Test %s (%s, %v): Unexpected error %v", name, test.pattern, test.follow, err)
+				}
+
+				if fmt.Sprint(matches) != fmt.Sprint(test.expected) {
+					t.Errorf("Test %s (%s, %v): Expected %q. Actual %q.", name, test.pattern, test.follow, test.expected, matches)
+				}
+			}
+		})
+	}
+}
+
+// Verify a link that is followed is reported as the file it points to, so
+// fn can skip a linked directory.
+func TestWalkerFollowSkipDir(t *testing.T) {
+	w := Walker{Workers: 4, Sorted: true, FollowSymlinks: FollowAlways}
+	var matches []string
+	err := w.Walk(context.Background(), linkFS, "conf/**", func(match string, d fs.DirEntry) error {
+		matches = append(matches, match)
+		if match == "conf/data" {
+			if !d.IsDir() || d.Name() != "data" {
+				t.Errorf("Expected the directory \"data\". Actual %q, a directory %t.", d.Name(), d.IsDir())
+			}
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	expected := []string{"conf/c.yaml", "conf/d.yaml", "conf/data", "conf/loop", "conf/self"}
+	if fmt.Sprint(matches) != fmt.Sprint(expected) {
+		t.Errorf("Expected %q. Actual %q.", expected, matches)
+	}
+}
+
+// Verify a broken link the walk would follow is reported as an error that
+// wraps ErrBrokenSymlink, without stopping the walk, whether or not the glob
+// pattern matches it.
+func TestWalkerBrokenSymlink(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/c.yaml":    {},
+		"conf/gone.yaml": symlink("nothing.yaml"),
+		"conf/gone":      symlink("nothing"),
+		"data/a.yaml":    {},
+	}
+
+	testIO := []struct {
+		follow   SymlinkPolicy
+		pattern  string
+		expected []string
+		broken   []string
+	}{
+		{FollowAlways, "**/*.yaml", []string{"conf/c.yaml", "data/a.yaml"}, []string{"conf/gone", "conf/gone.yaml"}},
+		{FollowAlways, "conf/gone", nil, []string{"conf/gone"}},
+		{FollowAlways, "conf/*.yaml", []string{"conf/c.yaml"}, []string{"conf/gone.yaml"}},
+		{FollowAlways, "data/*", []string{"data/a.yaml"}, nil},
+		{FollowRoot, "**/*.yaml", []string{"conf/c.yaml", "conf/gone.yaml", "data/a.yaml"}, nil},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			for _, sorted := range []bool{false, true} {
+				w := Walker{Workers: 4, Sorted: sorted, FollowSymlinks: test.follow}
+				matches, err := w.Glob(context.Background(), fsys, test.pattern)
+				if fmt.Sprint(matches) != fmt.Sprint(test.expected) {
+					t.Errorf("Test %s (%s, %v): Expected %q. Actual %q.", name, test.pattern, test.follow, test.expected, matches)
+				}
+
+				if (err != nil) != (len(test.broken) > 0) || err != nil && !errors.Is(err, ErrBrokenSymlink) {
+					t.Errorf("Test %s (%s, %v): Expected errors for %q. Actual %v.", name, test.pattern, test.follow, test.broken, err)
+				}
+
+				var broken []string
+				w.OnBrokenSymlink = func(err error) error {
+					var pathErr *fs.PathError
+					if !errors.As(err, &pathErr) || !errors.Is(err, ErrBrokenSymlink) {
+						t.Errorf("Test %s (%s, %v): Expected an *fs.PathError that wraps %v. Actual %v.", name, test.pattern, test.follow, ErrBrokenSymlink, err)
+					} else {
+						broken = append(broken, pathErr.Path)
+					}
+					return nil
+				}
+				if _, err := w.Glob(context.Background(), fsys, test.pattern); err != nil {
+					t.Errorf("Test %s (%s, %v): Unexpected error %v", name, test.pattern, test.follow, err)
+				}
+
+				sort.Strings(broken)
+				if fmt.Sprint(broken) != fmt.Sprint(test.broken) {
+					t.Errorf("Test %s (%s, %v): Expected broken links %q. Actual %q.", name, test.pattern, test.follow, test.broken, broken)
+				}
+			}
+		})
+	}
+}
+
+// Verify an error OnBrokenSymlink returns stops the walk.
+func TestWalkerOnBrokenSymlinkStops(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/gone": symlink("nothing"),
+		"a/x":    {},
+	}
+
+	stop := errors.New("stop")
+	w := Walker{FollowSymlinks: FollowAlways, OnBrokenSymlink: func(err error) error { return stop }}
+	if _, err := w.Glob(context.Background(), fsys, "a/*"); err != stop {
+		t.Errorf("Expected %v. Actual %v.", stop, err)
+	}
+}
+
+// statErrorFS fails to follow the symbolic links in a file system for a reason
+// other than the link being broken.
+type statErrorFS struct {
+	fstest.MapFS
+}
+
+func (fsys statErrorFS) Stat(name string) (fs.FileInfo, error) {
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrPermission}
+}
+
+// Verify an error following a link, other than it being broken, stops the
+// walk like an error reading a directory.
+func TestWalkerSymlinkError(t *testing.T) {
+	fsys := statErrorFS{fstest.MapFS{
+		"a/link": symlink("../b"),
+		"b/x":    {},
+	}}
+
+	w := Walker{FollowSymlinks: FollowAlways}
+	if _, err := w.Glob(context.Background(), fsys, "a/**"); !errors.Is(err, fs.ErrPermission) || errors.Is(err, ErrBrokenSymlink) {
+		t.Errorf("Expected %v. Actual %v.", fs.ErrPermission, err)
+	}
+}
+
+// Verify FollowNever doesn't follow a link to a directory the base directory
+// of the glob pattern is in.
+func TestWalkerFollowNeverBase(t *testing.T) {
+	fsys := fstest.MapFS{
+		"real/sub/x.yaml": {},
+		"top":             symlink("real"),
+	}
+
+	testIO := []struct {
+		follow   SymlinkPolicy
+		pattern  string
+		expected []string
+	}{
+		{FollowNever, "top/sub/*.yaml", nil},
+		{FollowNever, "top/sub", nil},
+		{FollowNever, "real/sub/*.yaml", []string{"real/sub/x.yaml"}},
+		{FollowRoot, "top/sub/*.yaml", []string{"top/sub/x.yaml"}},
+		{FollowAlways, "top/sub/*.yaml", []string{"top/sub/x.yaml"}},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			w := Walker{FollowSymlinks: test.follow}
+			matches, err := w.Glob(context.Background(), fsys, test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s, %v): Unexpected error %v", name, test.pattern, test.follow, err)
+			}
+
+			if fmt.Sprint(matches) != fmt.Sprint(test.expected) {
+				t.Errorf("Test %s (%s, %v): Expected %q. Actual %q.", name, test.pattern, test.follow, test.expected, matches)
+			}
+		})
+	}
+}
+
+func TestRealPath(t *testing.T) {
+	fsys := fstest.MapFS{
+		"abs":   symlink("/etc"),
+		"out":   symlink("../x"),
+		"spin":  symlink("spin"),
+		"chain": symlink("top/data"),
+	}
+	for name, file := range linkFS {
+		fsys[name] = file
+	}
+
+	testIO := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{".", ".", true},
+		{"data/sub", "data/sub", true},
+		{"conf/data", "data", true},
+		{"top/data/sub", "data/sub", true},
+		{"conf/loop/top/self", "conf", true},
+		{"chain/sub/b.yaml", "data/sub/b.yaml", true},
+		{"top/d.yaml", "data/a.yaml", true},
+		{"nothing", "", false},
+		{"abs", "", false},
+		{"out", "", false},
+		{"spin", "", false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			actual, ok := realPath(fsys, test.name)
+			if actual != test.expected || ok != test.ok {
+				t.Errorf("Test %s (%s): Expected %q, %t. Actual %q, %t.", name, test.name, test.expected, test.ok, actual, ok)
+			}
+		})
+	}
+}
+
+func TestSymlinkPolicyString(t *testing.T) {
+	testIO := []struct {
+		policy   SymlinkPolicy
+		expected string
+	}{
+		{FollowRoot, "root"},
+		{FollowNever, "never"},
+		{FollowAlways, "always"},
+		{SymlinkPolicy(7), "SymlinkPolicy(7)"},
+	}
+
+	for _, test := range testIO {
+		if actual := test.policy.String(); actual != test.expected {
+			t.Errorf("Expected %q. Actual %q.", test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"unicode"
+)
+
+// propertyEnd returns the offset of the '}' that ends the Unicode property,
+// such as "\p{Greek}" or "\P{L}", that starts with the escape character at
+// offset i in a class, or -1 if there isn't one there. A property name is made
+// of letters, digits and underscores.
+func (c *config) propertyEnd(class []rune, i int) int {
+	if i+3 >= len(class) || class[i] != c.escape || class[i+1] != 'p' && class[i+1] != 'P' || class[i+2] != '{' {
+		return -1
+	}
+
+	for j := i + 3; j < len(class); j++ {
+		switch {
+		case class[j] == '}':
+			return j
+		case !isASCIILetter(class[j]) && !isDigit(class[j]) && class[j] != '_':
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// propertyTable returns the table of the Unicode general category, script or
+// property with the given name, such as "L", "Greek" or "White_Space", or nil
+// if there isn't one.
+func propertyTable(name string) *unicode.RangeTable {
+	if table := unicode.Categories[name]; table != nil {
+		return table
+	}
+
+	if table := unicode.Scripts[name]; table != nil {
+		return table
+	}
+
+	return unicode.Properties[name]
+}
+
+// inProperty returns true if the character has the Unicode property, such as
+// "\p{Greek}", or doesn't have it, such as "\P{L}". A path separator never
+// matches a property. If the case of characters is folded, then a character
+// has a property if any case of it does.
+func (c *config) inProperty(property []rune, value rune) bool {
+	table := propertyTable(string(property[3 : len(property)-1]))
+	if table == nil || c.isSeparator(value) {
+		return false
+	}
+
+	has := unicode.Is(table, value)
+	if !has && c.foldCase {
+		for r := unicode.SimpleFold(value); r != value && !has; r = unicode.SimpleFold(r) {
+			has = unicode.Is(table, r)
+		}
+	}
+
+	return has == (property[1] == 'p')
+}
@@ -0,0 +1,168 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Verify classes may contain Unicode properties, mixed with other members.
+func TestUnicodeProperties(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{`[\p{Greek}]`, "λ", true},
+		{`[\p{Greek}]`, "l", false},
+		{`[\p{Han}]`, "漢", true},
+		{`[\p{Han}]`, "か", false},
+		{`[\p{L}]`, "ж", true},
+		{`[\p{L}]`, "1", false},
+		{`[\p{Lu}]`, "Ж", true},
+		{`[\p{Lu}]`, "ж", false},
+		{`[\p{Nd}]`, "٣", true},
+		{`[\p{White_Space}]`, " ", true},
+		{`[\P{L}]`, "1", true},
+		{`[\P{L}]`, "a", false},
+
+		// properties are mixed with ranges, literals and other members
+		{`[\p{Greek}0-9_]`, "7", true},
+		{`[\p{Greek}0-9_]`, "_", true},
+		{`[\p{Greek}0-9_]`, "a", false},
+		{`[\p{Han}\p{Hiragana}[:digit:]]`, "か", true},
+		{`[\p{Han}\p{Hiragana}[:digit:]]`, "5", true},
+
+		// negated classes, and negated properties
+		{`[!\p{L}]`, "1", true},
+		{`[!\p{L}]`, "a", false},
+		{`[!\P{L}]`, "a", true},
+		{`[!\p{Greek}\p{Nd}]`, "x", true},
+		{`[!\p{Greek}\p{Nd}]`, "λ", false},
+
+		// a path separator never has a property
+		{`a[\P{L}]b`, "a/b", false},
+		{`a[!\p{L}]b`, "a/b", false},
+
+		// the '}' of a property doesn't end anything else
+		{`{[\p{L}],x}y`, "źy", true},
+		{`*[\p{Nd}]*`, "ab٣cd", true},
+		{`[\p{L}]*.txt`, "étude.txt", true},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if _, err := braces.Validate(test.pattern); err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if matched, _ := braces.Match(test.pattern, test.path); matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+}
+
+// Verify unknown properties, and ranges that start or end with a property, are
+// reported.
+func TestUnicodePropertiesValidate(t *testing.T) {
+	testIO := []struct {
+		opts    Options
+		pattern string
+		offset  int
+		kind    error
+	}{
+		{Options{}, `[\p{Greek}]`, 0, nil},
+		{Options{}, `[\p{Greak}]`, 4, ErrGlobUnknownProperty},
+		{Options{}, `[a\P{}]`, 5, ErrGlobUnknownProperty},
+		{Options{}, `[\p{L}-z]`, 1, ErrGlobInvalidRange},
+		{Options{}, `[a-\p{L}]`, 1, ErrGlobInvalidRange},
+		{Options{}, `[\p{L}-]`, 0, nil},
+		{Options{}, `[\p{L}`, 6, ErrGlobTruncated},
+		{Options{}, `[\p]`, 2, ErrGlobInvalidEscape},
+		{Options{}, `[\p{L]`, 2, ErrGlobInvalidEscape},
+		{Options{LenientEscapes: true}, `[\p]`, 0, nil},
+		{Options{Escape: '`'}, "[`p{Greek}]", 0, nil},
+		{Options{Escape: '`'}, `[\p{Greek}]`, 0, nil}, // literal characters
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			_, err := test.opts.Validate(test.pattern)
+			if !errors.Is(err, test.kind) || (err == nil) != (test.kind == nil) {
+				t.Fatalf("Test %s (%s): Expected %v. Actual %v.", name, test.pattern, test.kind, err)
+			}
+
+			var patternErr *PatternError
+			if errors.As(err, &patternErr) && patternErr.Offset != test.offset {
+				t.Errorf("Test %s (%s): Expected offset %d. Actual %d.", name, test.pattern, test.offset, patternErr.Offset)
+			}
+
+			errs := test.opts.ValidateAll(test.pattern)
+			if (len(errs) == 0) != (test.kind == nil) || len(errs) > 0 && !errors.Is(errs[0], test.kind) {
+				t.Errorf("Test %s (%s): Expected ValidateAll to report %v first. Actual %v.", name, test.pattern, test.kind, errs)
+			}
+		})
+	}
+}
+
+// Verify the escape character introduces a property, and case folding applies
+// to properties.
+func TestUnicodePropertiesOptions(t *testing.T) {
+	testIO := []struct {
+		opts     Options
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{Options{Escape: '`'}, "[`p{Greek}]", "λ", true},
+		{Options{Escape: '`'}, `[\p{Greek}]`, "p", true},
+		{Options{Escape: '`'}, `[\p{Greek}]`, "λ", false},
+		{Options{FoldCase: true}, `[\p{Lu}]`, "ж", true},
+		{Options{FoldCase: true}, `[\P{Lu}]`, "ж", false},
+		{Options{FoldCase: true}, `[\p{Lu}]`, "1", false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if matched, _ := test.opts.Match(test.pattern, test.path); matched != test.expected {
+				t.Errorf("Test %s (%s, %s): Expected %t. Actual %t.", name, test.pattern, test.path, test.expected, matched)
+			}
+		})
+	}
+}
+
+func TestPropertyTable(t *testing.T) {
+	testIO := []struct {
+		name     string
+		expected bool
+	}{
+		{"L", true},
+		{"Greek", true},
+		{"White_Space", true},
+		{"greek", false},
+		{"", false},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			if actual := propertyTable(test.name) != nil; actual != test.expected {
+				t.Errorf("Test %s (%s): Expected %t. Actual %t.", name, test.name, test.expected, actual)
+			}
+		})
+	}
+}
@@ -2,27 +2,157 @@
 
 package glob
 
-// Validate the given glob pattern. Return true and the length of the pattern if
-// it's valid. Otherwise, return false and the zero-based index of the rune
-// where validation failed.
+import (
+	"errors"
+)
+
+// Validate the given glob pattern. Return the length of the pattern and nil if
+// it's valid. Otherwise, return the zero-based index of the rune where
+// validation failed and a *PatternError describing the problem. The index is
+// the error's Offset, except that it's the index of the last rune if the
+// pattern ended too soon, where Offset is the length of the pattern, so "ab["
+// returns 2 and an error at offset 3.
 func Validate(pattern string) (int, error) {
+	return defaultConfig.validate(pattern)
+}
+
+// validate is Validate, using the reserved symbols and names of the dialect.
+func (c *config) validate(pattern string) (int, error) {
 	var index int
+	var base int
 
-	head, tail, err := nextValidPattern([]rune(pattern))
+	// the errors locate invalid UTF-8 bytes exactly, whatever the mode
+	runes := decodeExact(pattern)
+	head, tail, err := c.nextValidPattern(runes, true)
 	index += len(head)
 	for err == nil && len(head) > 0 {
-		head, tail, err = nextValidPattern(tail)
+		base = index
+		head, tail, err = c.nextValidPattern(tail, false)
 		index += len(head)
 	}
 
+	var patternErr *PatternError
+	if errors.As(err, &patternErr) {
+		patternErr.locate(runes, base)
+	}
+
+	// report an error in the pattern as a whole unless there's one before it
+	if errs := c.scanPattern(runes); len(errs) > 0 && (err == nil || patternErr != nil && errs[0].Offset < patternErr.Offset) {
+		err, patternErr = errs[0], errs[0]
+	}
+
+	if patternErr != nil {
+		// the offset of a pattern that ended too soon is one past its end
+		if index = patternErr.Offset; index >= len(runes) && len(runes) > 0 {
+			index = len(runes) - 1
+		}
+	}
+
 	return index, err
 }
 
-func nextValidPattern(pattern []rune) (head, tail []rune, err error) {
+// ValidateAll validates the given glob pattern like Validate does, but instead
+// of stopping at the first error, it recovers after each bad class, bad escape
+// sequence or reserved symbol and keeps scanning. It returns a *PatternError
+// for each problem found, in the order they appear in the pattern, or nil if
+// the pattern is valid. The result may be passed to errors.Join.
+func ValidateAll(pattern string) []error {
+	return defaultConfig.validateAll(pattern)
+}
+
+// validateAll is ValidateAll, using the reserved symbols and names of the
+// dialect.
+func (c *config) validateAll(pattern string) []error {
+	var errs []error
+	var base int
+
+	// the errors locate invalid UTF-8 bytes exactly, whatever the mode
+	runes := decodeExact(pattern)
+	report := func(err *PatternError) bool {
+		errs = append(errs, err.locate(runes, base))
+		return true
+	}
+
+	head, tail, _ := c.scanNextPattern(runes, true, report)
+	for len(head) > 0 {
+		base += len(head)
+		head, tail, _ = c.scanNextPattern(tail, false, report)
+	}
+
+	// keep the errors in the order they appear in the pattern
+	for _, err := range c.scanPattern(runes) {
+		i := len(errs)
+		for i > 0 && errs[i-1].(*PatternError).Offset > err.Offset {
+			i--
+		}
+		errs = append(errs, nil)
+		copy(errs[i+1:], errs[i:])
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// scanPattern returns the errors that can only be found by scanning the glob
+// pattern as a whole, rather than one sub-pattern at a time, in the order they
+// appear in the pattern. These are unbalanced braces, invalid brace ranges and
+// reserved names.
+func (c *config) scanPattern(pattern []rune) []*PatternError {
+	var errs []*PatternError
+
+	report := func(err *PatternError) {
+		i := len(errs)
+		for i > 0 && errs[i-1].Offset > err.Offset {
+			i--
+		}
+		errs = append(errs, nil)
+		copy(errs[i+1:], errs[i:])
+		errs[i] = err.locate(pattern, 0)
+	}
+
+	c.scanBraces(pattern, report)
+	c.scanExtGlobs(pattern, report)
+	if offset := c.reservedName(pattern); offset >= 0 {
+		report(newPatternError(pattern, offset, ErrGlobReservedName))
+	}
+
+	return errs
+}
+
+// nextValidPattern breaks down a glob pattern like nextPattern does, but it
+// validates the sub-pattern as it goes and stops at the first error. The atStart
+// flag is true if the sub-pattern is at the start of the glob pattern.
+func (c *config) nextValidPattern(pattern []rune, atStart bool) (head, tail []rune, err error) {
+	head, tail, patternErr := c.scanNextPattern(pattern, atStart, func(*PatternError) bool {
+		return false
+	})
+
+	if patternErr != nil {
+		err = patternErr
+	}
+
+	return
+}
+
+// scanNextPattern finds the end of the next sub-pattern in a glob pattern. Each
+// error found is passed to report, along with its offset relative to the start
+// of the pattern. Scanning continues past the error if report returns true.
+// Otherwise, scanning stops and the error is returned. The atStart flag is true
+// if the sub-pattern is at the start of the glob pattern, where the Windows
+// dialect allows a drive designator.
+func (c *config) scanNextPattern(pattern []rune, atStart bool, report func(*PatternError) bool) (head, tail []rune, err *PatternError) {
 	var start int
 	var end int
 	var isEscaped bool
 
+	// fail reports an error and returns true if scanning must stop
+	fail := func(patternErr *PatternError) bool {
+		if !report(patternErr) {
+			err = patternErr
+		}
+		return err != nil
+	}
+
 	// If the pattern isn't empty and the first character is an asterisk, then
 	// it's either a directory or recursive pattern.
 	if len(pattern) > 0 && pattern[0] == '*' {
@@ -37,31 +167,39 @@ endPattern:
 	for end = start; err == nil && end < len(pattern); end++ {
 		token := pattern[end]
 
-		// reserved symbols cannot be found in a path, so reject the pattern
-		if isReservedSymbol(token) {
-			err = ErrGlobReservedSymbol
-			break
+		// reserved symbols cannot be found in a path, so reject the pattern.
+		// A '|' separates the patterns in an extended glob, and scanExtGlobs
+		// reports it if it's anywhere else.
+		if c.isReservedSymbol(token) && !(c.windows && atStart && c.isDriveColon(pattern, end)) && !(c.extGlob && token == '|') {
+			isEscaped = false
+			if fail(newPatternError(pattern, end, ErrGlobReservedSymbol)) {
+				break
+			}
+			continue
 		}
 
-		// Only wildcards and the escape character can be escaped.
 		if isEscaped {
 			isEscaped = false
-			if token != '?' && token != '[' && token != '*' && token != escapeCharacter {
-				err = ErrGlobInvalidEscape
-				break
-			} else {
-				continue
+			if !c.isEscapable(token, false) {
+				if fail(newPatternError(pattern, end, ErrGlobInvalidEscape)) {
+					break
+				}
 			}
+			continue
 		}
 
 		switch token {
-		case escapeCharacter:
+		case c.escape:
 			isEscaped = true
 		case '?':
 			continue
 		case '[':
-			var count int
-			count, err = classIsValid([]rune(pattern[end:]))
+			classStart := end
+			count := c.scanClass(pattern[end:], func(classErr *PatternError) bool {
+				// the class reports offsets relative to its left bracket
+				classErr.shift(classStart)
+				return !fail(classErr)
+			})
 			end += count - 1
 		case '*':
 			// end of current sub-pattern; '*' starts another one
@@ -74,3 +212,22 @@ endPattern:
 
 	return
 }
+
+// isEscapable returns true if the character may follow the escape character,
+// either in a class or outside of one. Unless escapes are lenient, only
+// wildcards, braces, commas and the escape character can be escaped outside of
+// a class, and only '!', '-', ']' and the escape character inside one.
+func (c *config) isEscapable(token rune, inClass bool) bool {
+	switch {
+	case c.lenientEscapes || token == c.escape:
+		return true
+	case inClass:
+		return token == '!' || token == '-' || token == ']' || c.caretNegation && token == '^'
+	case c.braces && (token == '{' || token == '}' || token == ','):
+		return true
+	case c.extGlob && (token == '(' || token == ')' || token == '|' || token == '+' || token == '@' || token == '!'):
+		return true
+	default:
+		return token == '?' || token == '[' || token == '*'
+	}
+}
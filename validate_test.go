@@ -3,6 +3,7 @@
 package glob
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -42,6 +43,8 @@ func TestValidate(t *testing.T) {
 		{"[]![]", nil, 5},
 		{"[-]", nil, 3},
 		{"[a-]", nil, 4},
+		{"[a-]x", nil, 5},
+		{"[a-]]", nil, 5},
 		{"[-b]", nil, 4},
 		{"[a-z]", nil, 5},
 		{"[?*\\\\]", nil, 6},
@@ -54,10 +57,96 @@ func TestValidate(t *testing.T) {
 		name := fmt.Sprintf("%03d", i+1)
 		t.Run(name, func(t *testing.T) {
 			length, err := Validate(test.pattern)
-			if test.err != err || test.length != length {
+			if !errors.Is(err, test.err) || test.length != length {
 				t.Errorf("Test %s: pattern(%s), expected (%d, %s), actual (%d, %s)",
 					name, test.pattern, test.length, test.err, length, err)
 			}
 		})
 	}
 }
+
+// Verify the index Validate returns is the offset of the error, or the last
+// rune of a pattern that ended too soon.
+func TestValidateIndex(t *testing.T) {
+	testIO := []struct {
+		pattern string
+		index   int
+		offset  int
+	}{
+		{"ab[", 2, 3},
+		{"[]", 1, 2},
+		{"a\\b", 2, 2},
+		{"[}?{\\*\\", 5, 5},
+		{"ab[!", 3, 4},
+		{"x[.-0]", 2, 2},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			index, err := Validate(test.pattern)
+			var patternErr *PatternError
+			if !errors.As(err, &patternErr) {
+				t.Fatalf("Test %s (%s): Expected a *PatternError. Actual %v.", name, test.pattern, err)
+			}
+
+			if index != test.index || patternErr.Offset != test.offset {
+				t.Errorf("Test %s (%s): Expected index %d and offset %d. Actual %d and %d.", name, test.pattern, test.index, test.offset, index, patternErr.Offset)
+			}
+		})
+	}
+}
+
+// Verify ValidateAll reports every error in a pattern.
+func TestValidateAll(t *testing.T) {
+	testIO := []struct {
+		pattern string
+		kinds   []error
+		offsets []int
+	}{
+		{"", nil, nil},
+		{"abc*def**[a-z]?", nil, nil},
+		{"\\b", []error{ErrGlobInvalidEscape}, []int{1}},
+		{"\\b*\\c", []error{ErrGlobInvalidEscape, ErrGlobInvalidEscape}, []int{1, 4}},
+		{"[\\x\\y]*a\\q", []error{ErrGlobInvalidEscape, ErrGlobInvalidEscape, ErrGlobInvalidEscape}, []int{2, 4, 9}},
+		{"[.-0]x\\b", []error{ErrGlobInvalidRange, ErrGlobInvalidEscape}, []int{1, 7}},
+		{"a\\b[", []error{ErrGlobInvalidEscape, ErrGlobTruncated}, []int{2, 4}},
+		{"[a-", []error{ErrGlobTruncated}, []int{3}},
+		{"[a\\", []error{ErrGlobTruncated}, []int{3}},
+		{string(rune(0x00)) + "*\\b" + string(rune(0x00)), []error{ErrGlobReservedSymbol, ErrGlobInvalidEscape, ErrGlobReservedSymbol}, []int{0, 3, 4}},
+	}
+
+	for i, test := range testIO {
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			errs := ValidateAll(test.pattern)
+			if len(errs) != len(test.kinds) {
+				t.Fatalf("Test %s: pattern(%s), expected %d errors, actual %d: %v", name, test.pattern, len(test.kinds), len(errs), errs)
+			}
+
+			for i, err := range errs {
+				var patternErr *PatternError
+				if !errors.As(err, &patternErr) {
+					t.Fatalf("Test %s[%02d]: expected a *PatternError. Actual %T.", name, i+1, err)
+				}
+
+				if !errors.Is(err, test.kinds[i]) || patternErr.Offset != test.offsets[i] {
+					t.Errorf("Test %s[%02d]: pattern(%s), expected (%s, %d), actual (%s, %d)",
+						name, i+1, test.pattern, test.kinds[i], test.offsets[i], patternErr.Kind, patternErr.Offset)
+				}
+			}
+
+			// The first error must be the one Validate reports.
+			_, err := Validate(test.pattern)
+			if len(errs) == 0 {
+				if err != nil {
+					t.Errorf("Test %s: pattern(%s), Validate returned %s but ValidateAll found no errors", name, test.pattern, err)
+				}
+			} else if err == nil || err.Error() != errs[0].Error() {
+				t.Errorf("Test %s: pattern(%s), Validate returned %v but ValidateAll's first error is %s", name, test.pattern, err, errs[0])
+			}
+		})
+	}
+}
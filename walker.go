@@ -0,0 +1,467 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Walker walks a file system with several goroutines at once, matching the
+// names in it against a glob pattern, for file systems where reading one
+// directory at a time is the bottleneck. It starts in the base directory of
+// the glob pattern and skips directories that nothing inside of could match,
+// as Glob does. The zero value reads runtime.GOMAXPROCS(0) directories at a
+// time, and reports the matches in the order they are found.
+type Walker struct {
+	// Options select how names are matched, as they do for Options.Glob.
+	Options Options
+
+	// Workers is the number of goroutines that read directories and match
+	// the names in them. If it's zero, it's runtime.GOMAXPROCS(0).
+	Workers int
+
+	// FanOut is the largest number of subdirectories of a directory that
+	// the worker that read it hands to the other workers. It walks the rest
+	// of them itself, one after another, which keeps the queue of
+	// directories short when there are many of them. If it's zero, every
+	// subdirectory is handed to the other workers.
+	FanOut int
+
+	// Sorted reports the matches in lexical order, as Glob returns them, so
+	// the output is the same from one walk to the next. The matches are
+	// reported once the walk is finished, so they are all held in memory
+	// until then.
+	Sorted bool
+
+	// FollowSymlinks selects the symbolic links the walk follows. The zero
+	// value, FollowRoot, only follows the base directory of the glob pattern,
+	// as Glob does.
+	FollowSymlinks SymlinkPolicy
+
+	// OnBrokenSymlink is called with an *fs.PathError that wraps
+	// ErrBrokenSymlink for each symbolic link the walk would follow that
+	// points to nothing, or to itself. It's called on the goroutine that
+	// called Walk, like fn. If it returns nil, the walk goes on, and if it
+	// returns an error, Walk stops and returns that error. If it's nil, the
+	// walk goes on, and Walk returns the errors joined together once the walk
+	// is finished.
+	OnBrokenSymlink func(err error) error
+}
+
+// Walk is like GlobWalk, but the walker's workers read the directories, so the
+// matches are found in no particular order unless Sorted is set. fn is called
+// on the goroutine that called Walk, one match at a time, so it doesn't need to
+// be safe for concurrent use.
+//
+// If fn returns fs.SkipDir for a directory, nothing inside of the directory is
+// reported, and if it returns fs.SkipDir for a file, nothing else in the
+// file's directory is. If it returns fs.SkipAll, Walk stops and returns nil,
+// and if it returns any other error, Walk stops and returns that error. Walk
+// also stops if reading a directory, or following a symbolic link, fails for
+// any reason except that it doesn't exist, or if the context is canceled, and
+// returns the error. A broken link doesn't stop it (see OnBrokenSymlink). It
+// always waits for its workers to stop before it returns.
+func (w Walker) Walk(ctx context.Context, fsys fs.FS, pattern string, fn func(name string, d fs.DirEntry) error) error {
+	// paths in an fs.FS always use '/' as their path separator
+	o := w.Options
+	o.Separator = '/'
+	p, err := o.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	base, match, ok, err := p.globBase(fsys)
+	if !ok {
+		return err
+	}
+
+	if w.FollowSymlinks == FollowNever && hasSymlink(fsys, base) {
+		// the glob pattern only matches names inside the base
+		return nil
+	}
+
+	workers := w.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	wk := &walk{ctx: ctx, fsys: fsys, pattern: p, fanOut: w.FanOut, follow: w.FollowSymlinks, onBroken: w.OnBrokenSymlink, results: make(chan walkResult, workers)}
+	wk.ready = sync.NewCond(&wk.mu)
+	defer context.AfterFunc(ctx, wk.stop)()
+
+	if p.CouldMatchUnder(base) {
+		wk.push(base)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wk.work()
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(wk.results)
+	}()
+
+	if w.Sorted {
+		err = wk.reportSorted(base, match, fn)
+	} else {
+		err = wk.reportFound(base, match, fn)
+	}
+
+	// stop the workers, and wait for them to stop
+	cancel()
+	for range wk.results {
+	}
+
+	if err == fs.SkipAll {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return errors.Join(wk.broken...)
+}
+
+// Glob is like the package-level Glob function, but the walker's workers read
+// the directories, and the walk stops if the context is canceled.
+func (w Walker) Glob(ctx context.Context, fsys fs.FS, pattern string) ([]string, error) {
+	var matches []string
+	err := w.Walk(ctx, fsys, pattern, func(name string, _ fs.DirEntry) error {
+		matches = append(matches, name)
+		return nil
+	})
+
+	sort.Strings(matches)
+	return matches, err
+}
+
+// walkResult is a match found by a worker, or an error. The error is either
+// from reading a directory, or it wraps ErrBrokenSymlink for the link with the
+// name.
+type walkResult struct {
+	name string
+	d    fs.DirEntry
+	err  error
+}
+
+// walk is a walk of a file system that its workers share. The directories
+// waiting to be read are a stack, so the walk goes deep before it goes wide,
+// and the stack stays short.
+type walk struct {
+	ctx     context.Context
+	fsys    fs.FS
+	pattern *Pattern
+	fanOut  int
+	follow  SymlinkPolicy
+	results chan walkResult
+
+	// onBroken is Walker.OnBrokenSymlink, and broken holds the errors for
+	// the broken links if it's nil. Only the goroutine that reports the
+	// results uses them.
+	onBroken func(err error) error
+	broken   []error
+
+	mu      sync.Mutex
+	ready   *sync.Cond      // signaled when a directory is pushed, or the walk ends
+	dirs    []string        // the directories waiting for a worker
+	pending int             // the directories waiting for a worker or being walked
+	stopped bool            // true if the walk was stopped early
+	skipped map[string]bool // the directories fn skipped
+}
+
+// work reads directories until there are none left, or the walk is stopped.
+func (wk *walk) work() {
+	for {
+		dir, ok := wk.next()
+		if !ok {
+			return
+		}
+
+		wk.walkDir(dir)
+		wk.done()
+	}
+}
+
+// walkDir reads the directory, and sends each file or directory in it that
+// matches the glob pattern to the results. It hands the subdirectories that
+// something inside of could match to the other workers, up to the fan-out,
+// and walks the rest itself.
+func (wk *walk) walkDir(dir string) {
+	p := wk.pattern
+	stack := []string{dir}
+
+	for len(stack) > 0 {
+		dir := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if wk.ctx.Err() != nil {
+			return
+		}
+		if wk.isSkipped(dir) {
+			continue
+		}
+
+		entries, err := fs.ReadDir(wk.fsys, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			// the directory was removed after its parent was read
+			continue
+		} else if err != nil {
+			wk.send(walkResult{err: err})
+			return
+		}
+
+		var subdirs []string
+		for _, d := range entries {
+			name := joinName(dir, d.Name())
+			descend := d.IsDir()
+
+			// only follow the links the glob pattern could match, or match
+			// something inside of
+			if d.Type()&fs.ModeSymlink != 0 && wk.follow == FollowAlways && (p.matchEntry(name, true) || p.CouldMatchUnder(name)) {
+				d, descend, err = wk.resolve(name)
+				if errors.Is(err, ErrBrokenSymlink) {
+					if !wk.send(walkResult{name: name, err: err}) {
+						return
+					}
+					continue
+				} else if err != nil {
+					wk.send(walkResult{err: err})
+					return
+				}
+			}
+
+			if p.matchEntry(name, d.IsDir()) && !wk.send(walkResult{name: name, d: d}) {
+				return
+			}
+
+			if descend && p.CouldMatchUnder(name) {
+				subdirs = append(subdirs, name)
+			}
+		}
+
+		n := len(subdirs)
+		if wk.fanOut > 0 && n > wk.fanOut {
+			n = wk.fanOut
+		}
+		wk.push(subdirs[:n]...)
+
+		// walk the rest in lexical order
+		for i := len(subdirs) - 1; i >= n; i-- {
+			stack = append(stack, subdirs[i])
+		}
+	}
+}
+
+// send sends a result to the goroutine that reports them. It returns false if
+// the walk was stopped.
+func (wk *walk) send(r walkResult) bool {
+	select {
+	case wk.results <- r:
+		return true
+	case <-wk.ctx.Done():
+		return false
+	}
+}
+
+// push adds directories to the stack of directories waiting for a worker.
+func (wk *walk) push(dirs ...string) {
+	if len(dirs) == 0 {
+		return
+	}
+
+	wk.mu.Lock()
+	wk.dirs = append(wk.dirs, dirs...)
+	wk.pending += len(dirs)
+	wk.mu.Unlock()
+
+	wk.ready.Broadcast()
+}
+
+// next waits for a directory to walk, and returns false if there won't be
+// another one, because every directory has been walked, or the walk was
+// stopped.
+func (wk *walk) next() (string, bool) {
+	wk.mu.Lock()
+	defer wk.mu.Unlock()
+
+	for len(wk.dirs) == 0 && wk.pending > 0 && !wk.stopped {
+		wk.ready.Wait()
+	}
+
+	if len(wk.dirs) == 0 || wk.stopped {
+		return "", false
+	}
+
+	dir := wk.dirs[len(wk.dirs)-1]
+	wk.dirs = wk.dirs[:len(wk.dirs)-1]
+	return dir, true
+}
+
+// done records that a worker has finished walking a directory it got from
+// next.
+func (wk *walk) done() {
+	wk.mu.Lock()
+	wk.pending--
+	last := wk.pending == 0
+	wk.mu.Unlock()
+
+	if last {
+		wk.ready.Broadcast()
+	}
+}
+
+// stop stops the walk early, waking the workers waiting for a directory.
+func (wk *walk) stop() {
+	wk.mu.Lock()
+	wk.stopped = true
+	wk.mu.Unlock()
+
+	wk.ready.Broadcast()
+}
+
+// skip records that nothing inside the directory should be reported.
+func (wk *walk) skip(dir string) {
+	wk.mu.Lock()
+	defer wk.mu.Unlock()
+
+	if wk.skipped == nil {
+		wk.skipped = map[string]bool{}
+	}
+	wk.skipped[dir] = true
+}
+
+// isSkipped returns true if the name is inside a directory that was skipped,
+// or is one.
+func (wk *walk) isSkipped(name string) bool {
+	wk.mu.Lock()
+	defer wk.mu.Unlock()
+
+	if len(wk.skipped) == 0 {
+		return false
+	}
+
+	for {
+		if wk.skipped[name] {
+			return true
+		}
+
+		i := strings.LastIndexByte(name, '/')
+		if i < 0 {
+			return false
+		}
+		name = name[:i]
+	}
+}
+
+// report calls fn for a match, or reports a broken link, unless it's inside a
+// directory that was skipped. It returns an error if the walk should stop.
+func (wk *walk) report(r walkResult, fn func(name string, d fs.DirEntry) error) error {
+	name, d := r.name, r.d
+	if wk.isSkipped(name) {
+		return nil
+	}
+
+	if r.err != nil {
+		if wk.onBroken != nil {
+			return wk.onBroken(r.err)
+		}
+
+		wk.broken = append(wk.broken, r.err)
+		return nil
+	}
+
+	err := fn(name, d)
+	if err != fs.SkipDir {
+		return err
+	}
+
+	if d.IsDir() {
+		wk.skip(name)
+		return nil
+	}
+
+	i := strings.LastIndexByte(name, '/')
+	if i < 0 {
+		// the file is in the root, so everything is skipped
+		return fs.SkipAll
+	}
+
+	wk.skip(name[:i])
+	return nil
+}
+
+// reportFound reports the base directory if it matched, and then each match
+// as the workers find it.
+func (wk *walk) reportFound(base string, match fs.DirEntry, fn func(name string, d fs.DirEntry) error) error {
+	if match != nil {
+		if err := wk.report(walkResult{name: base, d: match}, fn); err != nil {
+			return err
+		}
+	}
+
+	for r := range wk.results {
+		if r.err != nil && !errors.Is(r.err, ErrBrokenSymlink) {
+			return r.err
+		}
+
+		if err := wk.ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := wk.report(r, fn); err != nil {
+			return err
+		}
+	}
+
+	// the workers stop early if the context is canceled
+	return wk.ctx.Err()
+}
+
+// reportSorted waits for the workers to find every match, and then reports
+// them in lexical order.
+func (wk *walk) reportSorted(base string, match fs.DirEntry, fn func(name string, d fs.DirEntry) error) error {
+	var matches []walkResult
+	if match != nil {
+		matches = append(matches, walkResult{name: base, d: match})
+	}
+
+	for r := range wk.results {
+		if r.err != nil && !errors.Is(r.err, ErrBrokenSymlink) {
+			return r.err
+		}
+		matches = append(matches, r)
+	}
+
+	if err := wk.ctx.Err(); err != nil {
+		return err
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].name < matches[j].name
+	})
+
+	for _, r := range matches {
+		if err := wk.ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := wk.report(r, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
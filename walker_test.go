@@ -0,0 +1,327 @@
+// See LICENSE.txt for copyright and licensing information about this file.
+
+package glob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// treeFS returns a file system with a tree of directories, each of which has
+// width subdirectories, down to the depth, and a ".go" file and a ".md" file.
+func treeFS(depth, width int) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	var add func(dir string, depth int)
+	add = func(dir string, depth int) {
+		fsys[joinName(dir, "x.go")] = &fstest.MapFile{}
+		fsys[joinName(dir, "y.md")] = &fstest.MapFile{}
+		if depth == 0 {
+			return
+		}
+
+		for i := 0; i < width; i++ {
+			add(joinName(dir, fmt.Sprintf("d%d", i)), depth-1)
+		}
+	}
+
+	add(".", depth)
+	return fsys
+}
+
+// Verify a walker finds the same names as Glob, whatever its workers and
+// fan-out.
+func TestWalkerGlob(t *testing.T) {
+	tree := treeFS(4, 4)
+	testIO := []struct {
+		fsys    fs.FS
+		opts    Options
+		pattern string
+	}{
+		{tree, Options{}, "**"},
+		{tree, Options{}, "**.go"},
+		{tree, Options{}, "**/*.md"},
+		{tree, Options{}, "d1/**/x.go"},
+		{tree, Options{}, "*/d2/*/"},
+		{tree, Options{Braces: true}, "{d0,d3/d1}/**"},
+		{tree, Options{}, "d2/d2/y.md"},
+		{tree, Options{}, "d2/d2/"},
+		{tree, Options{}, "d2**"},
+		{tree, Options{}, "nothing/**"},
+		{tree, Options{FoldCase: true}, "D1/**/X.GO"},
+		{tree, Options{ExtGlob: true}, "!(d0)/**/*.go"},
+		{testFS, Options{}, "**"},
+		{testFS, Options{}, "src/**/*.go"},
+		{testFS, Options{}, "*/"},
+		{testFS, Options{NoHiddenDirs: true}, "**/*.go"},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			expected, err := test.opts.Glob(test.fsys, test.pattern)
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			for _, workers := range []int{0, 1, 2, 8} {
+				for _, fanOut := range []int{0, 1, 3} {
+					w := Walker{Options: test.opts, Workers: workers, FanOut: fanOut}
+					matches, err := w.Glob(context.Background(), test.fsys, test.pattern)
+					if err != nil {
+						t.Fatalf("Test %s (%s, %d workers, fan-out %d): Unexpected error %v", name, test.pattern, workers, fanOut, err)
+					}
+
+					if fmt.Sprint(matches) != fmt.Sprint(expected) {
+						t.Errorf("Test %s (%s, %d workers, fan-out %d): Expected %q. Actual %q.", name, test.pattern, workers, fanOut, expected, matches)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Verify a sorted walker reports the matches in lexical order, and skips
+// directories, the rest of a directory, or everything, as fn asks.
+func TestWalkerSorted(t *testing.T) {
+	testIO := []struct {
+		pattern  string
+		skip     string // the match for which fn returns err
+		err      error
+		expected []string
+	}{
+		{"src/**", "", nil, []string{"src/.git", "src/.git/config", "src/.git/hooks", "src/.git/hooks/x.go", "src/a", "src/a/b", "src/a/b/x.go", "src/a/b/y.txt", "src/internal", "src/internal/z.go", "src/main.go", "src/main_test.go"}},
+		{"**.md", "", nil, []string{"README.md", "docs/guide/intro.md", "docs/index.md", "vendor/lib/docs/index.md"}},
+		{"src/**", "src/a", fs.SkipDir, []string{"src/.git", "src/.git/config", "src/.git/hooks", "src/.git/hooks/x.go", "src/a", "src/internal", "src/internal/z.go", "src/main.go", "src/main_test.go"}},
+		{"src/**", "src/.git/config", fs.SkipDir, []string{"src/.git", "src/.git/config", "src/a", "src/a/b", "src/a/b/x.go", "src/a/b/y.txt", "src/internal", "src/internal/z.go", "src/main.go", "src/main_test.go"}},
+		{"src/**", "src/a/b", fs.SkipAll, []string{"src/.git", "src/.git/config", "src/.git/hooks", "src/.git/hooks/x.go", "src/a", "src/a/b"}},
+		{"*", "README.md", fs.SkipDir, []string{".env", "README.md"}},
+		{"src/a/", "src/a", fs.SkipDir, []string{"src/a"}},
+		{"src**", "src", fs.SkipDir, []string{"src"}},
+		{"src**", "src", fs.SkipAll, []string{"src"}},
+	}
+
+	for i, test := range testIO {
+		// There are less than 1000 tests, so each test can have a name of 3 digits
+		// with leading zeros
+		name := fmt.Sprintf("%03d", i+1)
+		t.Run(name, func(t *testing.T) {
+			w := Walker{Workers: 4, Sorted: true}
+			var matches []string
+			err := w.Walk(context.Background(), testFS, test.pattern, func(match string, d fs.DirEntry) error {
+				if d.Name() != match[strings.LastIndex(match, "/")+1:] {
+					t.Errorf("Test %s (%s): Unexpected entry %q for %q", name, test.pattern, d.Name(), match)
+				}
+
+				matches = append(matches, match)
+				if match == test.skip {
+					return test.err
+				}
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf("Test %s (%s): Unexpected error %v", name, test.pattern, err)
+			}
+
+			if fmt.Sprint(matches) != fmt.Sprint(test.expected) {
+				t.Errorf("Test %s (%s): Expected %q. Actual %q.", name, test.pattern, test.expected, matches)
+			}
+		})
+	}
+}
+
+// Verify a walker that isn't sorted never reports anything inside a directory
+// after fn skips it, since a directory is always found before what's in it.
+func TestWalkerSkipDir(t *testing.T) {
+	fsys := treeFS(4, 4)
+	w := Walker{Workers: 8, FanOut: 2}
+	var matches []string
+	err := w.Walk(context.Background(), fsys, "**", func(match string, d fs.DirEntry) error {
+		if strings.HasPrefix(match, "d1/") || strings.HasPrefix(match, "d2/d3/") {
+			t.Errorf("Unexpected match %q in a skipped directory", match)
+		}
+
+		matches = append(matches, match)
+		if match == "d1" || match == "d2/d3" {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	expected, _ := Glob(fsys, "**")
+	count := 0
+	for _, match := range expected {
+		if !strings.HasPrefix(match, "d1/") && !strings.HasPrefix(match, "d2/d3/") {
+			count++
+		}
+	}
+	if len(matches) != count {
+		t.Errorf("Expected %d matches. Actual %d.", count, len(matches))
+	}
+}
+
+// syncRecordingFS records each directory that is read, and is safe for
+// concurrent use.
+type syncRecordingFS struct {
+	fs.FS
+	mu   sync.Mutex
+	dirs []string
+}
+
+func (r *syncRecordingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	r.mu.Lock()
+	r.dirs = append(r.dirs, name)
+	r.mu.Unlock()
+	return fs.ReadDir(r.FS, name)
+}
+
+// Verify a walker stops with the error fn returns, an error reading a
+// directory, or when the context is canceled.
+func TestWalkerErrors(t *testing.T) {
+	fsys := treeFS(3, 4)
+	for _, sorted := range []bool{false, true} {
+		w := Walker{Workers: 4, Sorted: sorted}
+
+		errStop := errors.New("stop")
+		var count int
+		err := w.Walk(context.Background(), fsys, "**", func(string, fs.DirEntry) error {
+			count++
+			if count == 3 {
+				return errStop
+			}
+			return nil
+		})
+		if err != errStop || count != 3 {
+			t.Errorf("Sorted %t: Expected %v after 3 matches. Actual %v after %d.", sorted, errStop, err, count)
+		}
+
+		count = 0
+		err = w.Walk(context.Background(), fsys, "**", func(string, fs.DirEntry) error {
+			count++
+			if count == 3 {
+				return fs.SkipAll
+			}
+			return nil
+		})
+		if err != nil || count != 3 {
+			t.Errorf("Sorted %t: Expected no error after 3 matches. Actual %v after %d.", sorted, err, count)
+		}
+
+		matches, err := w.Glob(context.Background(), failingFS{FS: fsys, dir: "d2/d1", err: fs.ErrPermission}, "**")
+		if !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("Sorted %t: Expected %v. Actual %v.", sorted, fs.ErrPermission, err)
+		}
+		for _, match := range matches {
+			if strings.HasPrefix(match, "d2/d1/") {
+				t.Errorf("Sorted %t: Unexpected match %q in a directory that can't be read", sorted, match)
+			}
+		}
+
+		matches, err = w.Glob(context.Background(), failingFS{FS: fsys, dir: "d2/d1", err: fs.ErrNotExist}, "d2/**.go")
+		if err != nil || len(matches) != 16 {
+			t.Errorf("Sorted %t: Expected 16 matches and no error. Actual %d, %v.", sorted, len(matches), err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		count = 0
+		err = w.Walk(ctx, fsys, "**", func(string, fs.DirEntry) error {
+			count++
+			if count == 3 {
+				cancel()
+			}
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) || count != 3 {
+			t.Errorf("Sorted %t: Expected %v after 3 matches. Actual %v after %d.", sorted, context.Canceled, err, count)
+		}
+
+		rec := &syncRecordingFS{FS: fsys}
+		err = w.Walk(ctx, rec, "**", func(string, fs.DirEntry) error {
+			t.Errorf("Sorted %t: Expected no matches after the context was canceled", sorted)
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) || len(rec.dirs) != 0 {
+			t.Errorf("Sorted %t: Expected %v without reading a directory. Actual %v after reading %q.", sorted, context.Canceled, err, rec.dirs)
+		}
+	}
+
+	if err := (Walker{}).Walk(context.Background(), fsys, "[", nil); !errors.Is(err, ErrGlobTruncated) {
+		t.Errorf("Expected %v. Actual %v.", ErrGlobTruncated, err)
+	}
+}
+
+// slowFS takes a while to read a directory, and records the most directories
+// read at once.
+type slowFS struct {
+	fs.FS
+	reading atomic.Int32
+	most    atomic.Int32
+}
+
+func (s *slowFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	n := s.reading.Add(1)
+	defer s.reading.Add(-1)
+	for {
+		most := s.most.Load()
+		if n <= most || s.most.CompareAndSwap(most, n) {
+			break
+		}
+	}
+
+	time.Sleep(time.Millisecond)
+	return fs.ReadDir(s.FS, name)
+}
+
+// Verify a walker reads directories at the same time, but no more of them
+// than it has workers.
+func TestWalkerWorkers(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		fsys := &slowFS{FS: treeFS(3, 4)}
+		w := Walker{Workers: workers}
+		matches, err := w.Glob(context.Background(), fsys, "**.go")
+		if err != nil || len(matches) != 85 {
+			t.Errorf("%d workers: Expected 85 matches and no error. Actual %d, %v.", workers, len(matches), err)
+		}
+
+		most := int(fsys.most.Load())
+		if most > workers {
+			t.Errorf("%d workers: Expected at most %d directories read at once. Actual %d.", workers, workers, most)
+		}
+		if workers > 1 && most < 2 {
+			t.Errorf("%d workers: Expected directories to be read at once. Actual %d.", workers, most)
+		}
+	}
+}
+
+// Verify the matches of a walker are sorted if it's asked to sort them, even if
+// they are found in another order.
+func TestWalkerOrder(t *testing.T) {
+	fsys := treeFS(3, 4)
+	w := Walker{Workers: 8, Sorted: true}
+	var matches []string
+	err := w.Walk(context.Background(), fsys, "**", func(match string, _ fs.DirEntry) error {
+		matches = append(matches, match)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !sort.StringsAreSorted(matches) || len(matches) != 254 {
+		t.Errorf("Expected 254 sorted matches. Actual %d, sorted %t.", len(matches), sort.StringsAreSorted(matches))
+	}
+}